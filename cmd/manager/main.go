@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"time"
 
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
@@ -26,6 +27,7 @@ import (
 
 	"github.com/bigkevmcd/commit-status-tracker/pkg/apis"
 	"github.com/bigkevmcd/commit-status-tracker/pkg/controller"
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
 	"github.com/bigkevmcd/commit-status-tracker/version"
 )
 
@@ -37,6 +39,38 @@ var (
 )
 var log = logf.Log.WithName("cmd")
 
+var (
+	maxConcurrentReconciles = pflag.Int("max-concurrent-reconciles", 1, "maximum number of runs of each kind that are reconciled concurrently")
+
+	dashboardURL = pflag.String("dashboard-url", "", "base URL of a Tekton Dashboard to link commit statuses to")
+	storeSize    = pflag.Int("tracked-runs-max", 5000, "maximum number of reported states held in memory for each kind of run, must be greater than 0")
+	storeTTL     = pflag.Duration("tracked-runs-ttl", time.Hour, "how long reported states for completed runs are held in memory")
+
+	resyncInterval = pflag.Duration("resync-interval", 0, "how often to compare the statuses of recently completed runs with the Git hosting service, 0 disables resyncing")
+	resyncWindow   = pflag.Duration("resync-window", time.Hour, "how recently runs must have completed to be resynced")
+	resyncRate     = pflag.Float64("resync-rate", 1, "requests per second that resyncing can make to each Git hosting service")
+	resyncBurst    = pflag.Int("resync-burst", 5, "maximum burst of requests that resyncing can make to each Git hosting service")
+
+	finalizerTimeout = pflag.Duration("finalizer-timeout", time.Minute*5, "how long to retry reporting the status of a deleted run before allowing the deletion")
+
+	startupWindow = pflag.Duration("startup-window", time.Hour, "how recently runs must have been updated to be reconciled when the operator starts")
+	startupRate   = pflag.Float64("startup-rate", 5, "runs per second that are reconciled when the operator starts")
+	startupBurst  = pflag.Int("startup-burst", 10, "maximum burst of runs that are reconciled when the operator starts")
+
+	tokenFiles  = pflag.StringToString("token-file", nil, "files to read the token for each host from instead of secrets, e.g. github.com=/var/run/tokens/github, can be repeated")
+	tokenHelper = pflag.String("token-helper", "", "command to run to get the token for a host instead of reading secrets or files")
+
+	repoAllowlist = pflag.String("repo-allowlist", "", "YAML file of the repositories that runs in each namespace can report statuses for, empty allows all repositories")
+
+	credentialsNamespace = pflag.String("credentials-namespace", "", "namespace of central credentials for namespaces without a secret of their own, empty disables central credentials")
+
+	traceExporter     = pflag.String("trace-exporter", "", "exporter for traces of reconciles and requests to Git hosting services, either ocagent or empty to disable tracing")
+	traceAgentAddress = pflag.String("trace-agent-address", "", "address of the OpenCensus Agent that the ocagent exporter sends traces to, empty uses the agent's default address")
+	traceSampleRate   = pflag.Float64("trace-sample-rate", 1, "fraction of reconciles that are traced")
+
+	cancelSuperseded = pflag.Bool("cancel-superseded", false, "cancel pending PipelineRuns for older commits on a branch when a newer commit on the branch is run")
+)
+
 func printVersion() {
 	log.Info(fmt.Sprintf("Operator Version: %s", version.Version))
 	log.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
@@ -80,6 +114,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	stopTracing, err := tracker.StartTracing(*traceExporter, *traceAgentAddress, *traceSampleRate)
+	if err != nil {
+		log.Error(err, "Failed to start tracing")
+		os.Exit(1)
+	}
+	defer stopTracing()
+
 	ctx := context.TODO()
 	// Become the leader before proceeding
 	err = leader.Become(ctx, "commit-status-tracker-lock")
@@ -109,7 +150,50 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := controller.AddToManager(mgr); err != nil {
+	var allowlist tracker.RepoAllowlist
+	if *repoAllowlist != "" {
+		allowlist, err = tracker.LoadRepoAllowlist(*repoAllowlist)
+		if err != nil {
+			log.Error(err, "")
+			os.Exit(1)
+		}
+	}
+
+	opts := tracker.Options{
+		MaxConcurrentReconciles: *maxConcurrentReconciles,
+
+		DashboardURL: *dashboardURL,
+		StoreSize:    *storeSize,
+		StoreTTL:     *storeTTL,
+
+		ResyncInterval: *resyncInterval,
+		ResyncWindow:   *resyncWindow,
+		ResyncRate:     *resyncRate,
+		ResyncBurst:    *resyncBurst,
+
+		FinalizerTimeout: *finalizerTimeout,
+
+		StartupWindow: *startupWindow,
+		StartupRate:   *startupRate,
+		StartupBurst:  *startupBurst,
+
+		CancelSuperseded: *cancelSuperseded,
+
+		CredentialsNamespace: *credentialsNamespace,
+
+		TokenFiles:  *tokenFiles,
+		TokenHelper: *tokenHelper,
+
+		RepoAllowlist: allowlist,
+	}
+	if err := opts.Validate(); err != nil {
+		log.Error(err, "Invalid options")
+		os.Exit(1)
+	}
+	if opts.TokenHelper != "" || len(opts.TokenFiles) > 0 {
+		log.Info("WARNING: tokens are not read from secrets, so the secret annotations on runs and the central credential namespace policies are not applied")
+	}
+	if err := controller.AddToManager(mgr, opts); err != nil {
 		log.Error(err, "")
 		os.Exit(1)
 	}
@@ -120,6 +204,7 @@ func main() {
 	log.Info("Starting the Cmd.")
 	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
 		log.Error(err, "Manager exited non-zero")
+		stopTracing()
 		os.Exit(1)
 	}
 }
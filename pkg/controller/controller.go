@@ -2,15 +2,23 @@ package controller
 
 import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
 )
 
 // AddToManagerFuncs is a list of functions to add all Controllers to the Manager
-var AddToManagerFuncs []func(manager.Manager) error
+var AddToManagerFuncs []func(manager.Manager, tracker.Options) error
 
 // AddToManager adds all Controllers to the Manager
-func AddToManager(m manager.Manager) error {
+//
+// The Controllers share the locks for commits, so that runs of different
+// kinds can't report conflicting states for the same context on a commit.
+func AddToManager(m manager.Manager, opts tracker.Options) error {
+	if opts.CommitLocks == nil {
+		opts.CommitLocks = &tracker.KeyedMutex{}
+	}
 	for _, f := range AddToManagerFuncs {
-		if err := f(m); err != nil {
+		if err := f(m, opts); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+func TestAddToManagerSharesCommitLocks(t *testing.T) {
+	defer func(funcs []func(manager.Manager, tracker.Options) error) {
+		AddToManagerFuncs = funcs
+	}(AddToManagerFuncs)
+	locks := []*tracker.KeyedMutex{}
+	record := func(m manager.Manager, opts tracker.Options) error {
+		locks = append(locks, opts.CommitLocks)
+		return nil
+	}
+	AddToManagerFuncs = []func(manager.Manager, tracker.Options) error{record, record}
+
+	if err := AddToManager(nil, tracker.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if locks[0] == nil || locks[0] != locks[1] {
+		t.Fatalf("controllers got commit locks %p and %p, want the same locks", locks[0], locks[1])
+	}
+}
@@ -0,0 +1,79 @@
+package pipelinerun
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// Kind adapts PipelineRuns for tracking.
+type Kind struct{}
+
+var _ tracker.Kind = Kind{}
+
+// Name implements tracker.Kind.
+func (Kind) Name() string {
+	return "PipelineRun"
+}
+
+// New implements tracker.Kind.
+func (Kind) New() tracker.Run {
+	return &pipelinev1.PipelineRun{}
+}
+
+// List implements tracker.Kind.
+func (Kind) List(ctx context.Context, c client.Client, opts ...client.ListOption) ([]tracker.Run, error) {
+	runs := &pipelinev1.PipelineRunList{}
+	if err := c.List(ctx, runs, opts...); err != nil {
+		return nil, err
+	}
+	items := make([]tracker.Run, len(runs.Items))
+	for i := range runs.Items {
+		items[i] = &runs.Items[i]
+	}
+	return items, nil
+}
+
+// Wrap implements tracker.Kind.
+func (Kind) Wrap(r tracker.Run) tracker.Trackable {
+	return wrap(r.(*pipelinev1.PipelineRun))
+}
+
+// Times implements tracker.Kind.
+func (Kind) Times(r tracker.Run) (*metav1.Time, *metav1.Time) {
+	pr := r.(*pipelinev1.PipelineRun)
+	return pr.Status.StartTime, pr.Status.CompletionTime
+}
+
+// Conditions implements tracker.Kind, the children are the PipelineRun's
+// TaskRuns.
+func (Kind) Conditions(r tracker.Run) (*apis.Condition, map[string]*apis.Condition) {
+	pr := r.(*pipelinev1.PipelineRun)
+	children := make(map[string]*apis.Condition, len(pr.Status.TaskRuns))
+	for name, tr := range pr.Status.TaskRuns {
+		if tr.Status != nil {
+			children[name] = tr.Status.GetCondition(apis.ConditionSucceeded)
+		}
+	}
+	return pr.Status.GetCondition(apis.ConditionSucceeded), children
+}
+
+// Retries implements tracker.Kind, PipelineRuns are never retried.
+func (Kind) Retries(r tracker.Run) int {
+	return 0
+}
+
+// Cancel implements tracker.Kind.
+func (Kind) Cancel(r tracker.Run) bool {
+	pr := r.(*pipelinev1.PipelineRun)
+	if pr.IsCancelled() {
+		return false
+	}
+	pr.Spec.Status = pipelinev1.PipelineRunSpecStatusCancelled
+	return true
+}
@@ -1,10 +1,15 @@
 package pipelinerun
 
 import (
+	"sort"
+
 	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 )
 
+// Tekton labels PipelineRuns with the name of the Pipeline.
+const pipelineLabel = "tekton.dev/pipeline"
+
 type pipelineRunWrapper struct {
 	*pipelinev1.PipelineRun
 }
@@ -27,6 +32,66 @@ func (p pipelineRunWrapper) FindCommit() (*tracker.Commit, error) {
 	return tracker.FindCommit(extractPipelineResources(p.Spec.Resources))
 }
 
+// RunDetails returns the details of the PipelineRun for templating.
+func (p pipelineRunWrapper) RunDetails() tracker.RunDetails {
+	return tracker.RunDetails{
+		Kind:               "PipelineRun",
+		Name:               p.Name,
+		Namespace:          p.Namespace,
+		PipelineName:       p.pipelineName(),
+		Labels:             p.Labels,
+		Params:             tracker.ParamsToMap(p.Spec.Params),
+		Duration:           tracker.RunDuration(p.Status.StartTime, p.Status.CompletionTime),
+		FailedTasks:        p.failedTasks(),
+		Failure:            p.failure(),
+		State:              p.RunState(),
+		ServiceAccountName: p.Spec.ServiceAccountName,
+	}
+}
+
+func (p pipelineRunWrapper) pipelineName() string {
+	if p.Spec.PipelineRef != nil {
+		return p.Spec.PipelineRef.Name
+	}
+	return p.Labels[pipelineLabel]
+}
+
+func (p pipelineRunWrapper) failedTasks() []string {
+	failed := []string{}
+	for _, tr := range p.Status.TaskRuns {
+		if tr.Status == nil {
+			continue
+		}
+		if tracker.ConditionsToState(tr.Status.Conditions) == tracker.Failed {
+			failed = append(failed, tr.PipelineTaskName)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// failure describes the first failed TaskRun (ordered by the name of the
+// task in the Pipeline), falling back to the PipelineRun's condition if no
+// TaskRun failed, for example, if the PipelineRun timed out.
+func (p pipelineRunWrapper) failure() string {
+	if p.RunState() != tracker.Failed {
+		return ""
+	}
+	taskRuns := make([]*pipelinev1.PipelineRunTaskRunStatus, 0, len(p.Status.TaskRuns))
+	for _, tr := range p.Status.TaskRuns {
+		taskRuns = append(taskRuns, tr)
+	}
+	sort.Slice(taskRuns, func(i, j int) bool {
+		return taskRuns[i].PipelineTaskName < taskRuns[j].PipelineTaskName
+	})
+	for _, tr := range taskRuns {
+		if f := tracker.TaskRunFailure(tr.PipelineTaskName, tr.Status); f != "" {
+			return f
+		}
+	}
+	return tracker.ConditionsFailure(p.Status.Conditions)
+}
+
 func extractPipelineResources(bindings []pipelinev1.PipelineResourceBinding) []*pipelinev1.PipelineResourceSpec {
 	resources := make([]*pipelinev1.PipelineResourceSpec, len(bindings))
 	for i, b := range bindings {
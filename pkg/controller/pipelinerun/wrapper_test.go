@@ -3,11 +3,23 @@ package pipelinerun
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	ttb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1beta1"
 
 	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
 	tb "github.com/bigkevmcd/commit-status-tracker/test/builder"
 )
 
+var (
+	testNamespace   = "test-namespace"
+	pipelineRunName = "test-pipeline-run"
+)
+
 func TestFindCommitWithRepository(t *testing.T) {
 	pipelineRun := wrap(tb.MakePipelineRunWithResources(
 		tb.MakeGitResource("https://github.com/tektoncd/triggers", "master")))
@@ -24,3 +36,100 @@ func TestFindCommitWithRepository(t *testing.T) {
 		t.Fatalf("got %+v, want %+v", r, want)
 	}
 }
+
+func TestRunDetails(t *testing.T) {
+	start := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	pipelineRun := ttb.PipelineRun(pipelineRunName, testNamespace,
+		ttb.PipelineRunSpec("test-pipeline", ttb.PipelineRunParam("environment", "staging")),
+		ttb.PipelineRunLabel("app", "my-app"),
+		ttb.PipelineRunStatus(
+			ttb.PipelineRunStatusCondition(
+				apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}),
+			ttb.PipelineRunStartTime(start),
+			ttb.PipelineRunCompletionTime(start.Add(time.Minute*3)),
+			ttb.PipelineRunTaskRunsStatus("tr-test", taskRunStatus("test", corev1.ConditionFalse)),
+			ttb.PipelineRunTaskRunsStatus("tr-lint", taskRunStatus("lint", corev1.ConditionFalse)),
+			ttb.PipelineRunTaskRunsStatus("tr-build", taskRunStatus("build", corev1.ConditionTrue)),
+			ttb.PipelineRunTaskRunsStatus("tr-deploy", &pipelinev1.PipelineRunTaskRunStatus{PipelineTaskName: "deploy"}),
+		))
+	want := tracker.RunDetails{
+		Kind:         "PipelineRun",
+		Name:         pipelineRunName,
+		Namespace:    testNamespace,
+		PipelineName: "test-pipeline",
+		Labels:       map[string]string{"app": "my-app"},
+		Params:       map[string]string{"environment": "staging"},
+		Duration:     time.Minute * 3,
+		FailedTasks:  []string{"lint", "test"},
+		Failure:      "lint failed",
+		State:        tracker.Failed,
+	}
+
+	if d := wrap(pipelineRun).RunDetails(); !reflect.DeepEqual(d, want) {
+		t.Fatalf("RunDetails() got %#v, want %#v", d, want)
+	}
+}
+
+func TestRunDetailsFailure(t *testing.T) {
+	failedStep := &pipelinev1.PipelineRunTaskRunStatus{
+		PipelineTaskName: "build",
+		Status: &pipelinev1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse},
+				},
+			},
+			TaskRunStatusFields: pipelinev1.TaskRunStatusFields{
+				Steps: []pipelinev1.StepState{
+					{Name: "fetch", ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+					{Name: "compile", ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{ExitCode: 137, Reason: "OOMKilled"}}},
+				},
+			},
+		},
+	}
+	failureTests := []struct {
+		name      string
+		condition apis.Condition
+		taskRuns  map[string]*pipelinev1.PipelineRunTaskRunStatus
+		want      string
+	}{
+		{"successful run", apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue},
+			map[string]*pipelinev1.PipelineRunTaskRunStatus{"tr-build": taskRunStatus("build", corev1.ConditionTrue)}, ""},
+		{"failed step", apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse},
+			map[string]*pipelinev1.PipelineRunTaskRunStatus{
+				"tr-build": failedStep,
+				"tr-lint":  taskRunStatus("lint", corev1.ConditionTrue)},
+			"build failed in step compile: OOMKilled"},
+		{"timed out run", apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Message: "PipelineRun timed out"},
+			map[string]*pipelinev1.PipelineRunTaskRunStatus{"tr-build": taskRunStatus("build", corev1.ConditionTrue)},
+			"PipelineRun timed out"},
+	}
+
+	for _, tt := range failureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipelineRun := ttb.PipelineRun(pipelineRunName, testNamespace,
+				ttb.PipelineRunSpec("test-pipeline"),
+				ttb.PipelineRunStatus(ttb.PipelineRunStatusCondition(tt.condition)))
+			pipelineRun.Status.TaskRuns = tt.taskRuns
+
+			if f := wrap(pipelineRun).RunDetails().Failure; f != tt.want {
+				t.Errorf("RunDetails() got failure %#v, want %#v", f, tt.want)
+			}
+		})
+	}
+}
+
+func taskRunStatus(name string, s corev1.ConditionStatus) *pipelinev1.PipelineRunTaskRunStatus {
+	return &pipelinev1.PipelineRunTaskRunStatus{
+		PipelineTaskName: name,
+		Status: &pipelinev1.TaskRunStatus{
+			Status: duckv1.Status{
+				Conditions: duckv1.Conditions{
+					apis.Condition{Type: apis.ConditionSucceeded, Status: s},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// cancelSuperseded cancels the pending runs of the Kind, in the same
+// namespace, for older commits on the same branch of the repository that
+// report the same context, and reports that the run superseded them.
+//
+// Nothing is cancelled unless CancelSuperseded is enabled, and the run has a
+// branch, runs for the run's commit, either by ref or by its sha, are not
+// cancelled.
+func (r *Reconciler) cancelSuperseded(ctx context.Context, run tracker.Run, repo, ref, sha, context, token string, reqLogger logr.Logger) error {
+	branch := tracker.Branch(run)
+	if !r.options.CancelSuperseded || branch == "" {
+		return nil
+	}
+	runs, err := r.kind.List(ctx, r.client, client.InNamespace(run.GetNamespace()))
+	if err != nil {
+		return err
+	}
+	for _, other := range runs {
+		if other.GetName() == run.GetName() || other.GetDeletionTimestamp() != nil ||
+			tracker.Branch(other) != branch || !tracker.IsNewer(run, other) {
+			continue
+		}
+		w := r.kind.Wrap(other)
+		if !tracker.IsNotifiable(w) || w.RunState() != tracker.Pending {
+			continue
+		}
+		c, err := w.FindCommit()
+		if err != nil || c.Ref == ref {
+			continue
+		}
+		if otherRepo, err := c.Repo(); err != nil || otherRepo != repo {
+			continue
+		}
+		input, err := tracker.GetCommitStatusInput(w, r.clients.Client(token).Driver, r.options.DashboardURL)
+		if err != nil || input.Label != context {
+			continue
+		}
+		cancelled, err := r.cancel(ctx, other, repo, c, sha, context, run, token, reqLogger)
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			reqLogger.Info("cancelled a superseded "+r.kind.Name(), "superseded", other.GetName(), "sha", c.Ref)
+		}
+	}
+	return nil
+}
+
+// cancel reports that the run was superseded by the newer run, with the newer
+// run's credentials, and cancels it, returning false if the run can't be
+// cancelled, or if its commit resolves to the newer run's sha.
+func (r *Reconciler) cancel(ctx context.Context, run tracker.Run, repo string, c *tracker.Commit, newerSHA, context string, newer tracker.Run, token string, reqLogger logr.Logger) (bool, error) {
+	sha, err := r.resolveSHA(ctx, r.kind.Wrap(newer), c, repo, token, reqLogger)
+	if err != nil || sha == newerSHA {
+		return false, err
+	}
+	patch := client.MergeFrom(run.DeepCopyObject())
+	if !r.kind.Cancel(run) {
+		return false, nil
+	}
+	unlock := r.commits.Lock(keyForCommit(repo, sha, context))
+	defer unlock()
+
+	input := tracker.SupersededStatusInput(context, newer.GetName())
+	posted, err := r.createStatus(ctx, r.kind.Wrap(newer), c, repo, sha, token, input, reqLogger)
+	if err != nil {
+		return false, err
+	}
+	reported := tracker.NewReportedStatus(input, c)
+	reported.Retries = r.kind.Retries(run)
+	reported.Annotate(run)
+	posted.Annotate(run)
+	tracker.RemoveFinalizer(run)
+	if err := r.client.Patch(ctx, run, patch); err != nil {
+		return false, err
+	}
+	r.runs.Set(run.GetUID(), keyForRun(run.GetUID(), context, repo, c.Ref), tracker.Error)
+	r.recorder.Eventf(run, corev1.EventTypeNormal, reasonCancelled, "Cancelled, superseded by %s for commit %s", newer.GetName(), c.Ref)
+	return true, nil
+}
@@ -0,0 +1,165 @@
+package reconciler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+	ctb "github.com/bigkevmcd/commit-status-tracker/test/builder"
+)
+
+// The commits that the older and newer runs on a branch are for.
+const (
+	oldSHA = "a6b3dd2e3d1a11eab77f2e728ce881255aca1e26"
+	newSHA = "5aca1e264c5966ed2814158fd70eb649f214ec68"
+)
+
+func TestReconcileCancelsSupersededRuns(t *testing.T) {
+	cancelTests := []struct {
+		name          string
+		disabled      bool
+		olderBranch   string
+		olderContext  string
+		olderRef      string
+		olderStatus   corev1.ConditionStatus
+		wantCancelled bool
+	}{
+		{name: "older commit on the same branch", olderBranch: "feature", olderContext: "test-context",
+			olderRef: oldSHA, olderStatus: corev1.ConditionUnknown, wantCancelled: true},
+		{name: "cancelling disabled", disabled: true, olderBranch: "feature", olderContext: "test-context",
+			olderRef: oldSHA, olderStatus: corev1.ConditionUnknown},
+		{name: "different branch", olderBranch: "other", olderContext: "test-context",
+			olderRef: oldSHA, olderStatus: corev1.ConditionUnknown},
+		{name: "no branch", olderContext: "test-context",
+			olderRef: oldSHA, olderStatus: corev1.ConditionUnknown},
+		{name: "different context", olderBranch: "feature", olderContext: "other-context",
+			olderRef: oldSHA, olderStatus: corev1.ConditionUnknown},
+		{name: "same commit", olderBranch: "feature", olderContext: "test-context",
+			olderRef: newSHA, olderStatus: corev1.ConditionUnknown},
+		{name: "completed run", olderBranch: "feature", olderContext: "test-context",
+			olderRef: oldSHA, olderStatus: corev1.ConditionFalse},
+	}
+
+	k := testKinds[0]
+	for _, tt := range cancelTests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+			older := makeBranchRun(k, "older", tt.olderRef, tt.olderBranch, tt.olderContext, tt.olderStatus)
+			older.SetCreationTimestamp(metav1.NewTime(created))
+			newer := makeBranchRun(k, "newer", newSHA, "feature", "test-context", corev1.ConditionUnknown)
+			newer.SetCreationTimestamp(metav1.NewTime(created.Add(time.Minute)))
+			r, data := makeReconciler(k, older, newer, makeSecret())
+			r.options.CancelSuperseded = !tt.disabled
+
+			reconcileRun(t, r, "newer")
+
+			if c := getRun(t, r, "older").(*pipelinev1.PipelineRun).IsCancelled(); c != tt.wantCancelled {
+				t.Fatalf("older run cancelled got %v, want %v", c, tt.wantCancelled)
+			}
+			var want []*scm.Status
+			if tt.wantCancelled {
+				want = []*scm.Status{{State: scm.StateError, Label: "test-context", Desc: "superseded by newer"}}
+			}
+			if tt.olderRef != newSHA {
+				assertStatuses(t, data.Statuses[oldSHA], want)
+			}
+		})
+	}
+}
+
+// TestReconcileCancelledRun tests that a run that was cancelled when it was
+// superseded doesn't report its failure over the superseded status.
+func TestReconcileCancelledRun(t *testing.T) {
+	k := testKinds[0]
+	created := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	older := makeBranchRun(k, "older", oldSHA, "feature", "test-context", corev1.ConditionUnknown)
+	older.SetCreationTimestamp(metav1.NewTime(created))
+	newer := makeBranchRun(k, "newer", newSHA, "feature", "test-context", corev1.ConditionUnknown)
+	newer.SetCreationTimestamp(metav1.NewTime(created.Add(time.Minute)))
+	r, data := makeReconciler(k, older, newer, makeSecret())
+	r.options.CancelSuperseded = true
+
+	reconcileRun(t, r, "newer")
+	cancelled := getRun(t, r, "older")
+	k.setStatus(cancelled, corev1.ConditionFalse)
+	upsertRun(t, r, k, cancelled)
+	reconcileRun(t, r, "older")
+
+	assertStatuses(t, data.Statuses[oldSHA], []*scm.Status{
+		{State: scm.StateError, Label: "test-context", Desc: "superseded by newer"}})
+	if tracker.HasFinalizer(getRun(t, r, "older")) {
+		t.Fatal("finalizer was not removed from the cancelled run")
+	}
+}
+
+// TestReconcileDoesNotCancelTaskRuns tests that superseded TaskRuns are left
+// to complete.
+func TestReconcileDoesNotCancelTaskRuns(t *testing.T) {
+	k := testKinds[1]
+	created := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	older := makeBranchRun(k, "older", oldSHA, "feature", "test-context", corev1.ConditionUnknown)
+	older.SetCreationTimestamp(metav1.NewTime(created))
+	newer := makeBranchRun(k, "newer", newSHA, "feature", "test-context", corev1.ConditionUnknown)
+	newer.SetCreationTimestamp(metav1.NewTime(created.Add(time.Minute)))
+	r, data := makeReconciler(k, older, newer, makeSecret())
+	r.options.CancelSuperseded = true
+
+	reconcileRun(t, r, "newer")
+
+	if getRun(t, r, "older").(*pipelinev1.TaskRun).IsCancelled() {
+		t.Fatal("superseded TaskRun was cancelled")
+	}
+	assertStatuses(t, data.Statuses[oldSHA], nil)
+}
+
+// TestReconcileDoesNotCancelRunsForTheSameSHA tests that an older run for a
+// branch isn't cancelled by a newer run for the SHA that the branch points
+// to.
+func TestReconcileDoesNotCancelRunsForTheSameSHA(t *testing.T) {
+	k := testKinds[0]
+	created := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	older := makeBranchRun(k, "older", "master", "feature", "test-context", corev1.ConditionUnknown)
+	older.SetCreationTimestamp(metav1.NewTime(created))
+	newer := makeBranchRun(k, "newer", testSHA, "feature", "test-context", corev1.ConditionUnknown)
+	newer.SetCreationTimestamp(metav1.NewTime(created.Add(time.Minute)))
+	r, data := makeReconciler(k, older, newer, makeSecret())
+	r.options.CancelSuperseded = true
+
+	reconcileRun(t, r, "newer")
+
+	if getRun(t, r, "older").(*pipelinev1.PipelineRun).IsCancelled() {
+		t.Fatal("run for the same SHA was cancelled")
+	}
+	assertStatuses(t, data.Statuses[testSHA], []*scm.Status{{State: scm.StatePending, Label: "test-context", Desc: "testing"}})
+}
+
+// makeBranchRun returns a notifiable run of the kind, for the ref in
+// tektoncd/triggers, on the branch.
+func makeBranchRun(k testKind, name, ref, branch, context string, status corev1.ConditionStatus) tracker.Run {
+	annotations := notifiableAnnotations(context)
+	if branch != "" {
+		annotations[tracker.StatusBranchName] = branch
+	}
+	r := k.makeRunWith(name, status, annotations,
+		ctb.MakeGitResource("https://github.com/tektoncd/triggers", ref))
+	r.SetUID(types.UID(name))
+	return r
+}
+
+func assertStatuses(t *testing.T, got, want []*scm.Status) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d statuses, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if *got[i] != *want[i] {
+			t.Errorf("status %d got %#v, want %#v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// TestReconcileConcurrently reconciles many runs for the same commit from
+// several workers, run with -race to detect unsynchronised state.
+//
+// Only the newest run for each context is reported, the others are
+// superseded.
+func TestReconcileConcurrently(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		objs := []runtime.Object{makeSecret()}
+		names := []string{}
+		for i := 0; i < 40; i++ {
+			name := fmt.Sprintf("run-%02d", i)
+			objs = append(objs, k.makeNotifiableRun(name, types.UID(name), fmt.Sprintf("context-%d", i%4), corev1.ConditionUnknown))
+			names = append(names, name)
+		}
+		r, data := makeReconciler(k, objs...)
+		repos := trackConcurrentStatuses(t, r)
+
+		work := make(chan string)
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for name := range work {
+					if _, err := r.Reconcile(makeRequest(name)); err != nil {
+						t.Errorf("reconcile %s: %v", name, err)
+					}
+				}
+			}()
+		}
+		for _, name := range names {
+			work <- name
+		}
+		close(work)
+		wg.Wait()
+
+		if l := len(data.Statuses[testSHA]); l != 4 {
+			t.Fatalf("got %d statuses, want 4", l)
+		}
+		if repos.created != 4 {
+			t.Fatalf("got %d statuses created, want 4", repos.created)
+		}
+		for _, name := range names[len(names)-4:] {
+			if tracker.LastReported(k.Wrap(getRun(t, r, name))) == nil {
+				t.Errorf("no reported status recorded for %s", name)
+			}
+		}
+		if l := r.commits.Len(); l != 0 {
+			t.Fatalf("got %d commit locks after reconciling, want 0", l)
+		}
+	})
+}
+
+// concurrentStatuses wraps the fake RepositoryService, which isn't safe for
+// concurrent use, and fails the test if statuses for the same context on a
+// commit are created concurrently.
+type concurrentStatuses struct {
+	scm.RepositoryService
+	t        *testing.T
+	mu       sync.Mutex
+	inFlight map[string]int
+	created  int
+}
+
+func trackConcurrentStatuses(t *testing.T, r *Reconciler) *concurrentStatuses {
+	client := r.clients.Client(testToken)
+	c := &concurrentStatuses{RepositoryService: client.Repositories, t: t, inFlight: map[string]int{}}
+	client.Repositories = c
+	return c
+}
+
+func (c *concurrentStatuses) CreateStatus(ctx context.Context, repo, ref string, in *scm.StatusInput) (*scm.Status, *scm.Response, error) {
+	key := keyForCommit(repo, ref, in.Label)
+	c.mu.Lock()
+	c.inFlight[key]++
+	if n := c.inFlight[key]; n > 1 {
+		c.t.Errorf("%d concurrent statuses created for %s", n, key)
+	}
+	c.mu.Unlock()
+
+	// Widen the window for concurrent requests.
+	time.Sleep(time.Millisecond)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight[key]--
+	c.created++
+	return c.RepositoryService.CreateStatus(ctx, repo, ref, in)
+}
+
+// TestReconcileKindsShareCommitLocks tests that a run of one Kind waits for
+// the lock on its commit held for a run of another Kind.
+func TestReconcileKindsShareCommitLocks(t *testing.T) {
+	locks := &tracker.KeyedMutex{}
+	pipelineRuns, taskRuns := testKinds[0], testKinds[1]
+	pr, _ := makeReconciler(pipelineRuns, pipelineRuns.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue), makeSecret())
+	tr, data := makeReconciler(taskRuns, taskRuns.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue), makeSecret())
+	pr.commits, tr.commits = locks, locks
+
+	unlock := pr.commits.Lock(keyForCommit("tektoncd/triggers", testSHA, "test-context"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := tr.Reconcile(makeRequest("test-run")); err != nil {
+			t.Errorf("reconcile: %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("reconcile did not wait for the lock held for the other kind")
+	case <-time.After(time.Millisecond * 50):
+	}
+	unlock()
+	<-done
+	assertStatus(t, data, &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "testing"})
+}
+
+// TestReconcileLocksResolvedSHA tests that a run for a branch waits for the
+// lock held for the SHA that the branch points to.
+func TestReconcileLocksResolvedSHA(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		r, data := makeReconciler(k, k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue), makeSecret())
+
+		unlock := r.commits.Lock(keyForCommit("tektoncd/triggers", testSHA, "test-context"))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			if _, err := r.Reconcile(makeRequest("test-run")); err != nil {
+				t.Errorf("reconcile: %v", err)
+			}
+		}()
+
+		select {
+		case <-done:
+			t.Fatal("reconcile did not wait for the lock held for the resolved SHA")
+		case <-time.After(time.Millisecond * 50):
+		}
+		unlock()
+		<-done
+		assertStatus(t, data, &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "testing"})
+	})
+}
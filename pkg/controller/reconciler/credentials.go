@@ -0,0 +1,72 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// resolveSHA resolves the SHA of the commit with the client for the token,
+// statuses are posted for, and locked on, the SHA, so that runs for a branch
+// and for the commit that it points to can't report conflicting statuses.
+//
+// Refs that can't be resolved are counted as statuses that can't be
+// reported.
+func (r *Reconciler) resolveSHA(ctx context.Context, w tracker.Trackable, c *tracker.Commit, repo, token string, reqLogger logr.Logger) (string, error) {
+	var sha string
+	scmClient, res, err := r.withToken(ctx, w, c, token, reqLogger, func(scmClient *scm.Client) (res *scm.Response, err error) {
+		sha, res, err = tracker.ResolveSHA(ctx, scmClient, repo, c)
+		return res, err
+	})
+	if err != nil {
+		tracker.RecordStatusFailure(scmClient.Driver, res, err)
+		return "", err
+	}
+	return sha, nil
+}
+
+// createStatus reports the status for the commit's SHA with the client for
+// the token, and returns the details of the post.
+//
+// Statuses that are reported, and that can't be reported, are counted in
+// metrics.
+func (r *Reconciler) createStatus(ctx context.Context, w tracker.Trackable, c *tracker.Commit, repo, sha, token string, input *scm.StatusInput, reqLogger logr.Logger) (tracker.PostDetails, error) {
+	var s *scm.Status
+	scmClient, res, err := r.withToken(ctx, w, c, token, reqLogger, func(scmClient *scm.Client) (res *scm.Response, err error) {
+		s, res, err = scmClient.Repositories.CreateStatus(ctx, repo, sha, input)
+		return res, err
+	})
+	if err != nil {
+		tracker.RecordStatusFailure(scmClient.Driver, res, err)
+		return tracker.PostDetails{}, err
+	}
+	tracker.RecordStatusPosted(scmClient.Driver, input.State, w.GetNamespace())
+	return tracker.NewPostDetails(sha, s, res, r.clock()), nil
+}
+
+// withToken makes a request to the Git hosting service with the client for
+// the token, returning the client that made the last request.
+//
+// If the Git hosting service rejects the token, it may have been rotated
+// before the cache caught up, so the token for the run is refreshed, and the
+// request is made once more.
+func (r *Reconciler) withToken(ctx context.Context, w tracker.Trackable, c *tracker.Commit, token string, reqLogger logr.Logger, f func(*scm.Client) (*scm.Response, error)) (*scm.Client, *scm.Response, error) {
+	scmClient := r.clients.Client(token)
+	res, err := f(scmClient)
+	if !tracker.IsUnauthorized(res, err) {
+		return scmClient, res, err
+	}
+	r.clients.Forget(token)
+	reqLogger.Info("credentials were rejected, refreshing them")
+	token, rerr := r.tokens.Refresh(ctx, w, c)
+	if rerr != nil {
+		reqLogger.Error(rerr, "failed to refresh the credentials")
+		return scmClient, res, err
+	}
+	scmClient = r.clients.Client(token)
+	res, err = f(scmClient)
+	return scmClient, res, err
+}
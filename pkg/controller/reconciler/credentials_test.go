@@ -0,0 +1,104 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+	"github.com/bigkevmcd/commit-status-tracker/test"
+	ctb "github.com/bigkevmcd/commit-status-tracker/test/builder"
+)
+
+// TestReconcileWithRotatedToken tests that when the cached token is
+// rejected, the secret is read again, and the status is reported with the
+// rotated token.
+func TestReconcileWithRotatedToken(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		r, data := makeReconciler(k, run, makeSecret())
+		r.tokens = tracker.NewSecretTokenProvider(r.client, fake.NewFakeClientWithScheme(makeScheme(),
+			ctb.MakeSecret(tracker.SecretName, map[string][]byte{"token": []byte("rotated-token")})), "")
+		rejectTokens(r, testToken)
+
+		reconcileRun(t, r, "test-run")
+
+		wanted := &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "testing", Target: ""}
+		assertStatus(t, data, wanted)
+	})
+}
+
+// TestReconcileWithRejectedToken tests that the status is only retried once
+// when the token is rejected.
+func TestReconcileWithRejectedToken(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		r, data := makeReconciler(k, run, makeSecret())
+		rejectTokens(r, testToken)
+
+		_, err := r.Reconcile(makeRequest("test-run"))
+
+		if !test.MatchError(t, "Bad credentials", err) {
+			t.Fatalf("unexpected error returned: %s", err)
+		}
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// rejectTokens replaces the Reconciler's clients, so that statuses created
+// with the tokens are rejected by the Git hosting service.
+func rejectTokens(r *Reconciler, tokens ...string) {
+	accepted := r.clients.Client(testToken)
+	r.clients = tracker.NewClientCache(func(token string) *scm.Client {
+		for _, rejected := range tokens {
+			if token == rejected {
+				return &scm.Client{Driver: accepted.Driver, Git: accepted.Git, Repositories: unauthorizedStatuses{accepted.Repositories}}
+			}
+		}
+		return accepted
+	})
+}
+
+// unauthorizedStatuses rejects the credentials for all statuses that are
+// created.
+type unauthorizedStatuses struct {
+	scm.RepositoryService
+}
+
+func (unauthorizedStatuses) CreateStatus(ctx context.Context, repo, ref string, in *scm.StatusInput) (*scm.Status, *scm.Response, error) {
+	return nil, &scm.Response{Status: http.StatusUnauthorized}, errors.New("Bad credentials")
+}
+
+func TestWatchSecrets(t *testing.T) {
+	secretGVK := corev1.SchemeGroupVersion.WithKind("Secret")
+	providerTests := []struct {
+		name   string
+		tokens tracker.TokenProvider
+		want   bool
+	}{
+		{"secrets", tracker.NewSecretTokenProvider(fake.NewFakeClient(), fake.NewFakeClient(), ""), true},
+		{"files", tracker.NewFileTokenProvider(map[string]string{"github.com": "/tokens/github"}), false},
+		{"helper", tracker.NewExecTokenProvider("/bin/token-helper"), false},
+	}
+
+	for _, tt := range providerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := makeReconciler(testKinds[0])
+			r.tokens = tt.tokens
+			informers := &informertest.FakeInformers{Scheme: makeScheme()}
+
+			if err := watchSecrets(informers, r); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := informers.InformersByGVK[secretGVK]; ok != tt.want {
+				t.Fatalf("Secret informer requested got %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
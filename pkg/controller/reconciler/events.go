@@ -0,0 +1,43 @@
+package reconciler
+
+import (
+	"errors"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// These are the reasons for the Events that are recorded on runs.
+const (
+	reasonStatusReported       = "StatusReported"
+	reasonStatusFailed         = "StatusFailed"
+	reasonCancelled            = "Cancelled"
+	reasonGitResourceNotFound  = "GitResourceNotFound"
+	reasonMultipleGitResources = "MultipleGitResources"
+	reasonInvalidGitResource   = "InvalidGitResource"
+	reasonInvalidRepository    = "InvalidRepository"
+	reasonRepositoryNotAllowed = "RepositoryNotAllowed"
+	reasonCredentialsNotFound  = "CredentialsNotFound"
+)
+
+// These are the reasons for skipping runs that have no Event, runs are
+// counted by the reason they were skipped in metrics.
+const (
+	reasonNotNotifiable   = "NotNotifiable"
+	reasonInvalidTemplate = "InvalidTemplate"
+	reasonAlreadyReported = "AlreadyReported"
+	reasonRunCompleted    = "RunCompleted"
+	reasonSuperseded      = "Superseded"
+)
+
+// commitErrorReason returns the reason for an error finding the commit for a
+// run.
+func commitErrorReason(err error) string {
+	switch {
+	case errors.Is(err, tracker.ErrNoGitResource):
+		return reasonGitResourceNotFound
+	case errors.Is(err, tracker.ErrMultipleGitResources):
+		return reasonMultipleGitResources
+	default:
+		return reasonInvalidGitResource
+	}
+}
@@ -0,0 +1,92 @@
+package reconciler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+	ctb "github.com/bigkevmcd/commit-status-tracker/test/builder"
+)
+
+func TestReconcileRecordsEvents(t *testing.T) {
+	eventTests := []struct {
+		name    string
+		makeRun func(k testKind) tracker.Run
+		secret  bool
+		reject  bool
+		want    string
+	}{
+		{
+			name: "status reported",
+			makeRun: func(k testKind) tracker.Run {
+				return k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+			},
+			secret: true,
+			want:   `Normal StatusReported Reported success status for context "test-context" to tektoncd/triggers`,
+		},
+		{
+			name: "no git resource",
+			makeRun: func(k testKind) tracker.Run {
+				return k.makeRunWith("test-run", corev1.ConditionUnknown, notifiableAnnotations("test-context"))
+			},
+			secret: true,
+			want:   "Warning GitResourceNotFound failed to find a git resource",
+		},
+		{
+			name: "multiple git resources",
+			makeRun: func(k testKind) tracker.Run {
+				return k.makeRunWith("test-run", corev1.ConditionUnknown, notifiableAnnotations("test-context"),
+					ctb.MakeGitResource("https://github.com/tektoncd/triggers", "master"),
+					ctb.MakeGitResource("https://github.com/tektoncd/pipeline", "master"))
+			},
+			secret: true,
+			want:   "Warning MultipleGitResources found multiple git resources",
+		},
+		{
+			name: "unparseable repository",
+			makeRun: func(k testKind) tracker.Run {
+				return k.makeRunWith("test-run", corev1.ConditionUnknown, notifiableAnnotations("test-context"),
+					ctb.MakeGitResource("https://github.com/tektoncd", "master"))
+			},
+			secret: true,
+			want:   "Warning InvalidRepository could not determine repo from URL",
+		},
+		{
+			name: "missing secret",
+			makeRun: func(k testKind) tracker.Run {
+				return k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+			},
+			want: "Warning CredentialsNotFound failed to GetAuthSecret",
+		},
+		{
+			name: "scm error",
+			makeRun: func(k testKind) tracker.Run {
+				return k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+			},
+			secret: true,
+			reject: true,
+			want:   `Warning StatusFailed Failed to report success status for context "test-context" to tektoncd/triggers: Bad credentials`,
+		},
+	}
+
+	forEachKind(t, func(t *testing.T, k testKind) {
+		for _, tt := range eventTests {
+			t.Run(tt.name, func(t *testing.T) {
+				objs := []runtime.Object{tt.makeRun(k)}
+				if tt.secret {
+					objs = append(objs, makeSecret())
+				}
+				r, _ := makeReconciler(k, objs...)
+				if tt.reject {
+					rejectTokens(r, testToken)
+				}
+
+				r.Reconcile(makeRequest("test-run"))
+
+				assertEvent(t, r, tt.want)
+			})
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// finalize reports a terminal status for a deleted run if the last status
+// that was reported was pending, and then removes the finalizer.
+//
+// If the status can't be reported, this is retried until the FinalizerTimeout
+// has passed, so that deletion can't be blocked forever.
+func (r *Reconciler) finalize(ctx context.Context, run tracker.Run, reqLogger logr.Logger) (reconcile.Result, error) {
+	if last := tracker.LastReported(r.kind.Wrap(run)); last != nil && last.State == scm.StatePending {
+		if err := r.reportDeletion(ctx, run, last, reqLogger); err != nil {
+			if !tracker.FinalizerExpired(run, r.options.FinalizerTimeout, r.clock()) {
+				reqLogger.Error(err, "failed to report the status of a deleted "+r.kind.Name()+", retrying")
+				return reconcile.Result{}, err
+			}
+			reqLogger.Error(err, "failed to report the status of a deleted "+r.kind.Name()+", giving up")
+		}
+	}
+	patch := client.MergeFrom(run.DeepCopyObject())
+	tracker.RemoveFinalizer(run)
+	return reconcile.Result{}, r.client.Patch(ctx, run, patch)
+}
+
+// reportDeletion reports an error for the last reported context, unless the
+// run completed before it was deleted, when its status is reported.
+//
+// Nothing is reported if a newer run has superseded the run.
+func (r *Reconciler) reportDeletion(ctx context.Context, run tracker.Run, last *tracker.ReportedStatus, reqLogger logr.Logger) error {
+	w := r.kind.Wrap(run)
+	res, err := r.findCommit(ctx, w)
+	if err != nil {
+		return err
+	}
+	repo, err := res.Repo()
+	if err != nil {
+		return err
+	}
+	if err := r.options.RepoAllowlist.CheckRepo(w.GetNamespace(), repo); err != nil {
+		reqLogger.Info("not reporting the deletion for a repository that is not allowed", "repo", repo)
+		r.recorder.Event(run, corev1.EventTypeWarning, reasonRepositoryNotAllowed, err.Error())
+		return nil
+	}
+	secret, err := r.token(ctx, w, res)
+	if err != nil {
+		return err
+	}
+	sha, err := r.resolveSHA(ctx, w, res, repo, secret, reqLogger)
+	if err != nil {
+		return err
+	}
+	unlock := r.commits.Lock(keyForCommit(repo, sha, last.Context))
+	defer unlock()
+
+	scmClient := r.clients.Client(secret)
+	newer, err := r.newerRun(ctx, run, repo, res.Ref, last.Context, scmClient.Driver)
+	if err != nil {
+		return err
+	}
+	if newer != nil {
+		reqLogger.Info("not reporting the deletion of a superseded run", "supersededBy", newer.GetName())
+		return nil
+	}
+	input := tracker.DeletedStatusInput(last.Context)
+	if w.RunState() != tracker.Pending {
+		input, err = tracker.GetCommitStatusInput(w, scmClient.Driver, r.options.DashboardURL)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := r.createStatus(ctx, w, res, repo, sha, secret, input, reqLogger); err != nil {
+		r.recorder.Eventf(run, corev1.EventTypeWarning, reasonStatusFailed, "Failed to report %s status for context %q to %s: %s",
+			input.State, input.Label, repo, err)
+		return err
+	}
+	r.recorder.Eventf(run, corev1.EventTypeNormal, reasonStatusReported, "Reported %s status for context %q to %s",
+		input.State, input.Label, repo)
+	return nil
+}
@@ -0,0 +1,138 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// TestReconcileAddsFinalizer tests that a finalizer is added to pending runs,
+// and removed when they complete.
+func TestReconcileAddsFinalizer(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+		r, _ := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+		updated := getRun(t, r, "test-run")
+		if !tracker.HasFinalizer(updated) {
+			t.Fatal("finalizer was not added to the pending run")
+		}
+
+		k.setStatus(updated, corev1.ConditionTrue)
+		fatalIfError(t, r.client.Update(context.TODO(), updated), "update: (%v)", updated)
+		reconcileRun(t, r, "test-run")
+		if tracker.HasFinalizer(getRun(t, r, "test-run")) {
+			t.Fatal("finalizer was not removed from the completed run")
+		}
+	})
+}
+
+func TestReconcileDeletedRun(t *testing.T) {
+	deletedTests := []struct {
+		name          string
+		condition     corev1.ConditionStatus
+		deleted       time.Duration
+		secret        bool
+		wantErr       bool
+		wantFinalizer bool
+		wantStatus    *scm.Status
+	}{
+		{"pending run", corev1.ConditionUnknown, time.Minute, true, false, false,
+			&scm.Status{State: scm.StateError, Label: "test-context", Desc: "run was deleted before completion"}},
+		{"completed run", corev1.ConditionFalse, time.Minute, true, false, false,
+			&scm.Status{State: scm.StateFailure, Label: "test-context", Desc: "testing"}},
+		{"failed to report", corev1.ConditionUnknown, time.Minute, false, true, true, nil},
+		{"failed to report after the timeout", corev1.ConditionUnknown, time.Minute * 10, false, false, false, nil},
+	}
+
+	forEachKind(t, func(t *testing.T, k testKind) {
+		for _, tt := range deletedTests {
+			t.Run(tt.name, func(t *testing.T) {
+				run := k.makeNotifiableRun("test-run", "test-uid", "test-context", tt.condition)
+				addAnnotations(run, reportedAnnotations("pending"))
+				run.SetFinalizers([]string{tracker.Finalizer})
+				run.SetDeletionTimestamp(&metav1.Time{Time: testTime.Add(-tt.deleted)})
+				objs := []runtime.Object{run}
+				if tt.secret {
+					objs = append(objs, makeSecret())
+				}
+				r, data := makeReconciler(k, objs...)
+				r.options.FinalizerTimeout = time.Minute * 5
+
+				_, err := r.Reconcile(makeRequest("test-run"))
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("reconcile got error %v, wantErr %v", err, tt.wantErr)
+				}
+				if f := tracker.HasFinalizer(getRun(t, r, "test-run")); f != tt.wantFinalizer {
+					t.Fatalf("got finalizer %v, want %v", f, tt.wantFinalizer)
+				}
+				if tt.wantStatus == nil {
+					assertNoStatusesRecorded(t, data)
+					return
+				}
+				assertStatus(t, data, tt.wantStatus)
+			})
+		}
+	})
+}
+
+// TestReconcileDeletedRunWithoutAnnotation tests that a run that stopped
+// being notifiable after the finalizer was added can still be deleted.
+func TestReconcileDeletedRunWithoutAnnotation(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		old := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+		addAnnotations(old, reportedAnnotations("pending"))
+		old.SetFinalizers([]string{tracker.Finalizer})
+		delete(old.GetAnnotations(), tracker.NotifiableName)
+		deleted := old.DeepCopyObject().(tracker.Run)
+		deleted.SetDeletionTimestamp(&metav1.Time{Time: testTime})
+		r, data := makeReconciler(k, deleted, makeSecret())
+
+		if !notifiableRuns(k).Update(event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: deleted, ObjectNew: deleted}) {
+			t.Fatal("deletion of a run with the finalizer was filtered")
+		}
+		reconcileRun(t, r, "test-run")
+
+		if tracker.HasFinalizer(getRun(t, r, "test-run")) {
+			t.Fatal("finalizer was not removed")
+		}
+		assertStatus(t, data, &scm.Status{State: scm.StateError, Label: "test-context", Desc: "run was deleted before completion"})
+	})
+}
+
+// TestReconcileDeletedRunUntilTimeout tests that reporting the status of a
+// deleted run is retried until the FinalizerTimeout has passed.
+func TestReconcileDeletedRunUntilTimeout(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+		addAnnotations(run, reportedAnnotations("pending"))
+		run.SetFinalizers([]string{tracker.Finalizer})
+		run.SetDeletionTimestamp(&metav1.Time{Time: testTime})
+		r, data := makeReconciler(k, run)
+		r.options.FinalizerTimeout = time.Minute * 5
+
+		if _, err := r.Reconcile(makeRequest("test-run")); err == nil {
+			t.Fatal("expected an error reporting the status of the deleted run")
+		}
+		if !tracker.HasFinalizer(getRun(t, r, "test-run")) {
+			t.Fatal("finalizer was removed before the timeout")
+		}
+
+		r.clock = func() time.Time { return testTime.Add(time.Minute*5 + time.Second) }
+		reconcileRun(t, r, "test-run")
+
+		if tracker.HasFinalizer(getRun(t, r, "test-run")) {
+			t.Fatal("finalizer was not removed after the timeout")
+		}
+		assertNoStatusesRecorded(t, data)
+	})
+}
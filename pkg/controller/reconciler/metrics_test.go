@@ -0,0 +1,80 @@
+package reconciler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func TestReconcileCountsPostedStatuses(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		labels := map[string]string{"provider": "fake", "state": "success", "namespace": testNamespace}
+		before := metricValue(t, "commit_status_tracker_statuses_posted_total", labels)
+		r, _ := makeReconciler(k, k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue), makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		assertMetricValue(t, "commit_status_tracker_statuses_posted_total", labels, before+1)
+	})
+}
+
+func TestReconcileCountsFailedStatuses(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		labels := map[string]string{"provider": "fake", "class": "unauthorized"}
+		before := metricValue(t, "commit_status_tracker_status_failures_total", labels)
+		r, _ := makeReconciler(k, k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue), makeSecret())
+		rejectTokens(r, testToken)
+
+		r.Reconcile(makeRequest("test-run"))
+
+		assertMetricValue(t, "commit_status_tracker_status_failures_total", labels, before+1)
+	})
+}
+
+func TestReconcileCountsSkippedRuns(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		labels := map[string]string{"kind": k.Name(), "reason": reasonAlreadyReported}
+		r, _ := makeReconciler(k, k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue), makeSecret())
+		reconcileRun(t, r, "test-run")
+		before := metricValue(t, "commit_status_tracker_skipped_runs_total", labels)
+
+		reconcileRun(t, r, "test-run")
+
+		assertMetricValue(t, "commit_status_tracker_skipped_runs_total", labels, before+1)
+	})
+}
+
+func assertMetricValue(t *testing.T, name string, labels map[string]string, want float64) {
+	t.Helper()
+	if v := metricValue(t, name, labels); v != want {
+		t.Fatalf("%s%v got %v, want %v", name, labels, v, want)
+	}
+}
+
+// metricValue returns the value of the counter with the labels from the
+// controller-runtime metrics registry.
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			matched := 0
+			for _, l := range m.GetLabel() {
+				if labels[l.GetName()] == l.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
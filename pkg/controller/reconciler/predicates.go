@@ -0,0 +1,84 @@
+package reconciler
+
+import (
+	"reflect"
+
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// notifiableRuns filters out the events for runs that aren't notifiable.
+//
+// Runs with the finalizer are never filtered out, even if they're no longer
+// notifiable, so that the finalizer is removed when they're deleted.
+func notifiableRuns(k tracker.Kind) predicate.Funcs {
+	notifiable := func(o interface{}) bool {
+		r, ok := o.(tracker.Run)
+		return ok && (tracker.IsNotifiable(k.Wrap(r)) || tracker.HasFinalizer(r))
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return notifiable(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return notifiable(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return notifiable(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return notifiable(e.Object)
+		},
+	}
+}
+
+// statusChanges filters out updates that can't change the status that is
+// reported for a run, only updates that change the Succeeded condition of
+// the run or its children, its annotations, or delete it are reconciled.
+func statusChanges(k tracker.Kind) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(tracker.Run)
+			if !ok {
+				return true
+			}
+			updated, ok := e.ObjectNew.(tracker.Run)
+			if !ok {
+				return true
+			}
+			return statusChanged(k, old, updated)
+		},
+	}
+}
+
+func statusChanged(k tracker.Kind, old, updated tracker.Run) bool {
+	if !reflect.DeepEqual(old.GetAnnotations(), updated.GetAnnotations()) {
+		return true
+	}
+	if (old.GetDeletionTimestamp() == nil) != (updated.GetDeletionTimestamp() == nil) {
+		return true
+	}
+	oldSucceeded, oldChildren := k.Conditions(old)
+	succeeded, children := k.Conditions(updated)
+	if !conditionEqual(oldSucceeded, succeeded) || len(oldChildren) != len(children) {
+		return true
+	}
+	for name, c := range children {
+		if !conditionEqual(oldChildren[name], c) {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionEqual compares the parts of the conditions that are reported,
+// ignoring when they last changed.
+func conditionEqual(a, b *apis.Condition) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Status == b.Status && a.Reason == b.Reason && a.Message == b.Message
+}
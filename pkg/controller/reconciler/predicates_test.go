@@ -0,0 +1,158 @@
+package reconciler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+func TestNotifiableRuns(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		p := notifiableRuns(k)
+		notifiable := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+		other := k.makeRunWith("other-run", corev1.ConditionUnknown, nil)
+
+		if !p.Create(event.CreateEvent{Meta: notifiable, Object: notifiable}) {
+			t.Error("create event for a notifiable run was filtered")
+		}
+		if p.Create(event.CreateEvent{Meta: other, Object: other}) {
+			t.Error("create event for a run that isn't notifiable was not filtered")
+		}
+		if p.Update(event.UpdateEvent{MetaOld: other, ObjectOld: other, MetaNew: other, ObjectNew: other}) {
+			t.Error("update event for a run that isn't notifiable was not filtered")
+		}
+		if !p.Delete(event.DeleteEvent{Meta: notifiable, Object: notifiable}) {
+			t.Error("delete event for a notifiable run was filtered")
+		}
+	})
+}
+
+func TestStatusChanges(t *testing.T) {
+	changeTests := []struct {
+		name   string
+		update func(k testKind, r tracker.Run)
+		want   bool
+	}{
+		{"no change", func(k testKind, r tracker.Run) {}, false},
+		{"labels changed", func(k testKind, r tracker.Run) {
+			r.SetLabels(map[string]string{"progress": "1"})
+		}, false},
+		{"completion time changed", func(k testKind, r tracker.Run) {
+			k.setCompletionTime(r, time.Now())
+		}, false},
+		{"succeeded", func(k testKind, r tracker.Run) {
+			k.setStatus(r, corev1.ConditionTrue)
+		}, true},
+		{"annotations changed", func(k testKind, r tracker.Run) {
+			addAnnotations(r, reportedAnnotations("pending"))
+		}, true},
+		{"deleted", func(k testKind, r tracker.Run) {
+			r.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+		}, true},
+	}
+
+	forEachKind(t, func(t *testing.T, k testKind) {
+		for _, tt := range changeTests {
+			t.Run(tt.name, func(t *testing.T) {
+				old := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+				updated := old.DeepCopyObject().(tracker.Run)
+				tt.update(k, updated)
+
+				e := event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: updated, ObjectNew: updated}
+				if b := statusChanges(k).Update(e); b != tt.want {
+					t.Fatalf("statusChanges() got %v, want %v", b, tt.want)
+				}
+			})
+		}
+	})
+}
+
+func TestStatusChangesWithChildTaskRuns(t *testing.T) {
+	k := testKinds[0]
+	old := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown).(*pipelinev1.PipelineRun)
+	old.Status.TaskRuns = map[string]*pipelinev1.PipelineRunTaskRunStatus{
+		"test-run-build": {PipelineTaskName: "build", Status: &pipelinev1.TaskRunStatus{}},
+	}
+	old.Status.TaskRuns["test-run-build"].Status.SetCondition(
+		&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionUnknown})
+
+	updated := old.DeepCopy()
+	updated.Status.TaskRuns["test-run-build"].Status.PodName = "test-run-build-pod"
+	if statusChanged(k, old, updated) {
+		t.Error("statusChanged() with a new pod got true, want false")
+	}
+
+	updated.Status.TaskRuns["test-run-build"].Status.SetCondition(
+		&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	if !statusChanged(k, old, updated) {
+		t.Error("statusChanged() with a completed TaskRun got false, want true")
+	}
+
+	updated = old.DeepCopy()
+	updated.Status.TaskRuns["test-run-test"] = &pipelinev1.PipelineRunTaskRunStatus{
+		PipelineTaskName: "test", Status: &pipelinev1.TaskRunStatus{}}
+	if !statusChanged(k, old, updated) {
+		t.Error("statusChanged() with a new TaskRun got false, want true")
+	}
+}
+
+// BenchmarkUpdateStream reconciles a synthetic stream of updates, where most
+// of the updates are for progress that doesn't change the reported status, or
+// for runs that aren't notifiable, with and without the predicates.
+func BenchmarkUpdateStream(b *testing.B) {
+	for _, k := range testKinds {
+		for _, filtered := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%s/filtered=%v", k.Name(), filtered), func(b *testing.B) {
+				events := makeUpdateStream(k, 100)
+				r, _ := makeReconciler(k, events[len(events)-2].ObjectNew, events[len(events)-1].ObjectNew, makeSecret())
+				notifiable, changes := notifiableRuns(k), statusChanges(k)
+
+				reconciles := 0
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					for _, e := range events {
+						if filtered && !(notifiable.Update(e) && changes.Update(e)) {
+							continue
+						}
+						if _, err := r.Reconcile(makeRequest(e.MetaNew.GetName())); err != nil {
+							b.Fatal(err)
+						}
+						reconciles++
+					}
+				}
+				b.ReportMetric(float64(reconciles)/float64(b.N), "reconciles/op")
+			})
+		}
+	}
+}
+
+// makeUpdateStream returns n updates, alternating between a notifiable run
+// and one that isn't, the notifiable run succeeds halfway through the
+// stream, the other updates only record progress.
+func makeUpdateStream(k testKind, n int) []event.UpdateEvent {
+	runs := []tracker.Run{
+		k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown),
+		k.makeRunWith("other-run", corev1.ConditionUnknown, nil),
+	}
+	events := []event.UpdateEvent{}
+	for i := 0; i < n; i++ {
+		old := runs[i%2]
+		updated := old.DeepCopyObject().(tracker.Run)
+		updated.SetLabels(map[string]string{"progress": fmt.Sprint(i)})
+		if i == n/2 {
+			k.setStatus(updated, corev1.ConditionTrue)
+		}
+		events = append(events, event.UpdateEvent{MetaOld: old, ObjectOld: old, MetaNew: updated, ObjectNew: updated})
+		runs[i%2] = updated
+	}
+	return events
+}
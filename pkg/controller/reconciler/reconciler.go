@@ -0,0 +1,334 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opencensus.io/trace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// AddFor returns a function that adds a Controller for the Kind to a Manager.
+func AddFor(k tracker.Kind) func(manager.Manager, tracker.Options) error {
+	return func(mgr manager.Manager, opts tracker.Options) error {
+		return Add(mgr, k, opts)
+	}
+}
+
+// Add creates a new Controller for the Kind and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+//
+// A StartupReconciler is added to the Manager to catch up with recently
+// updated runs, and if a ResyncInterval is configured, a Resyncer is also
+// added.
+func Add(mgr manager.Manager, k tracker.Kind, opts tracker.Options) error {
+	r := newReconciler(mgr, k, opts)
+	err := mgr.Add(tracker.NewStartupReconciler("startup-"+pluralName(k), r, listUpdated(k, r.client), opts))
+	if err != nil {
+		return err
+	}
+	if opts.ResyncInterval > 0 {
+		err = mgr.Add(tracker.NewResyncer(r.tokens, r.clients.Client, listCompleted(k, r.client), r.isSuperseded, opts))
+		if err != nil {
+			return err
+		}
+	}
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager, k tracker.Kind, opts tracker.Options) *Reconciler {
+	commits := opts.CommitLocks
+	if commits == nil {
+		commits = &tracker.KeyedMutex{}
+	}
+	return &Reconciler{
+		kind:     k,
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		tokens:   tracker.NewTokenProvider(mgr.GetClient(), mgr.GetAPIReader(), opts),
+		clients:  tracker.NewClientCache(tracker.CreateSCMClient),
+		recorder: mgr.GetEventRecorderFor("commit-status-tracker"),
+		runs:     tracker.NewStore(pluralName(k), opts.StoreSize, opts.StoreTTL),
+		commits:  commits,
+		options:  opts,
+		clock:    time.Now,
+		log:      logf.Log.WithName("controller_" + strings.ToLower(k.Name())),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+//
+// Only events for notifiable runs that can change the reported status are
+// reconciled, and runs that already exist when the Controller is added are
+// left to the StartupReconciler.
+//
+// Secrets are read from the Manager's cache, and the SCM clients for the
+// tokens in them are discarded when they change.
+func add(mgr manager.Manager, r *Reconciler) error {
+	if err := watchSecrets(mgr.GetCache(), r); err != nil {
+		return err
+	}
+
+	c, err := controller.New(strings.ToLower(r.kind.Name())+"-controller", mgr, controller.Options{
+		Reconciler:              r,
+		MaxConcurrentReconciles: r.options.MaxConcurrentReconciles,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: r.kind.New()}, &tracker.ForgetOnDelete{Store: r.runs},
+		notifiableRuns(r.kind), statusChanges(r.kind), tracker.LiveEvents(time.Now()))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// watchSecrets discards the SCM clients for the tokens in Secrets when they
+// change, if the tokens are read from Secrets, otherwise Secrets are not
+// watched at all, so that the operator doesn't need to be allowed to list
+// them.
+func watchSecrets(c cache.Cache, r *Reconciler) error {
+	if _, ok := r.tokens.(*tracker.SecretTokenProvider); !ok {
+		return nil
+	}
+	secrets, err := c.GetInformer(&corev1.Secret{})
+	if err != nil {
+		return err
+	}
+	secrets.AddEventHandler(tracker.PurgeOnSecretChange(r.clients))
+	return nil
+}
+
+// Reconciler reconciles runs of a Kind, reporting their state to the Git
+// hosting service.
+type Reconciler struct {
+	kind tracker.Kind
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client   client.Client
+	scheme   *runtime.Scheme
+	tokens   tracker.TokenProvider
+	clients  *tracker.ClientCache
+	recorder record.EventRecorder
+	// The last reported status is recorded in annotations on the run, this
+	// only covers the window where the cache has not caught up with them.
+	runs tracker.Store
+	// Statuses for the same context on a commit are reported one at a time,
+	// so that concurrent reconciles can't report conflicting states, the
+	// locks are shared with the Reconcilers for the other Kinds.
+	commits *tracker.KeyedMutex
+	options tracker.Options
+	clock   func() time.Time
+	log     logr.Logger
+}
+
+// Reconcile reads that state of the cluster for a run and makes changes based on the state read
+// and what is in the run's Spec
+// Note:
+// The Controller will requeue the Request to be processed again if the returned error is non-nil or
+// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+//
+// Each Reconcile is traced, with the run and its commit as attributes.
+func (r *Reconciler) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	return r.traceReconcile(context.Background(), request)
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	reqLogger := r.log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.Info("Reconciling " + r.kind.Name())
+
+	// Fetch the run instance
+	run := r.kind.New()
+	err := r.client.Get(ctx, request.NamespacedName, run)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if run.GetDeletionTimestamp() != nil {
+		if tracker.HasFinalizer(run) {
+			return r.finalize(ctx, run, reqLogger)
+		}
+		return reconcile.Result{}, nil
+	}
+
+	w := r.kind.Wrap(run)
+	if !tracker.IsNotifiable(w) {
+		reqLogger.Info("not a notifiable run")
+		r.skip(reasonNotNotifiable)
+		return reconcile.Result{}, nil
+	}
+
+	res, err := r.findCommit(ctx, w)
+	if err != nil {
+		reqLogger.Error(err, "failed to find a git resource")
+		r.recorder.Event(run, corev1.EventTypeWarning, commitErrorReason(err), err.Error())
+		r.skip(commitErrorReason(err))
+		return reconcile.Result{}, nil
+	}
+	reqLogger.Info("found a git resource", "resource", res)
+
+	repo, err := res.Repo()
+	if err != nil {
+		reqLogger.Error(err, "could not parse git repository into a repo")
+		r.recorder.Event(run, corev1.EventTypeWarning, reasonInvalidRepository, err.Error())
+		r.skip(reasonInvalidRepository)
+		return reconcile.Result{}, err
+	}
+	if err := r.options.RepoAllowlist.CheckRepo(request.Namespace, repo); err != nil {
+		reqLogger.Info("not reporting the status for a repository that is not allowed", "repo", repo)
+		r.recorder.Event(run, corev1.EventTypeWarning, reasonRepositoryNotAllowed, err.Error())
+		r.skip(reasonRepositoryNotAllowed)
+		return reconcile.Result{}, nil
+	}
+	secret, err := r.token(ctx, w, res)
+	if err != nil {
+		reqLogger.Error(err, "failed to get an authSecret")
+		r.recorder.Event(run, corev1.EventTypeWarning, reasonCredentialsNotFound, err.Error())
+		r.skip(reasonCredentialsNotFound)
+		return reconcile.Result{}, nil
+	}
+
+	// TODO: this should be using the URL.
+	scmClient := r.clients.Client(secret)
+	commitStatusInput, err := tracker.GetCommitStatusInput(w, scmClient.Driver, r.options.DashboardURL)
+	if err != nil {
+		reqLogger.Error(err, "failed to render the commit status")
+		r.skip(reasonInvalidTemplate)
+		return reconcile.Result{}, nil
+	}
+	trace.FromContext(ctx).AddAttributes(
+		trace.StringAttribute("repo", repo),
+		trace.StringAttribute("context", commitStatusInput.Label),
+		trace.StringAttribute("state", commitStatusInput.State.String()))
+	key := keyForRun(run.GetUID(), commitStatusInput.Label, repo, res.Ref)
+	status := w.RunState()
+	if last, ok := r.runs.Get(key); ok && status == last {
+		r.skip(reasonAlreadyReported)
+		return reconcile.Result{}, nil
+	}
+	reported := tracker.NewReportedStatus(commitStatusInput, res)
+	reported.Retries = r.kind.Retries(run)
+	last := tracker.LastReported(w)
+	if last.Matches(reported) {
+		r.skip(reasonAlreadyReported)
+		return reconcile.Result{}, nil
+	}
+	if from, ok := r.lastState(key, last, reported); ok && !tracker.ValidTransition(from, status) && !tracker.IsRetry(last, reported.Retries) {
+		reqLogger.Info("not reporting a state transition for a completed run", "from", from, "to", status)
+		tracker.RecordRejectedTransition(r.kind.Name(), from, status)
+		r.skip(reasonRunCompleted)
+		return reconcile.Result{}, nil
+	}
+	// The run's own state was checked without the lock, as each run is only
+	// reconciled by one worker at a time, other runs for the same context on
+	// the commit are checked with it held.
+	sha, err := r.resolveSHA(ctx, w, res, repo, secret, reqLogger)
+	if err != nil {
+		r.recorder.Eventf(run, corev1.EventTypeWarning, reasonStatusFailed, "Failed to report %s status for context %q to %s: %s",
+			commitStatusInput.State, commitStatusInput.Label, repo, err)
+		return reconcile.Result{}, err
+	}
+	unlock := r.commits.Lock(keyForCommit(repo, sha, commitStatusInput.Label))
+	defer unlock()
+	newer, err := r.newerRun(ctx, run, repo, res.Ref, commitStatusInput.Label, scmClient.Driver)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if newer != nil {
+		reqLogger.Info("not reporting the status of a superseded run", "supersededBy", newer.GetName())
+		r.skip(reasonSuperseded)
+		return reconcile.Result{}, nil
+	}
+	if status == tracker.Pending {
+		if err := r.cancelSuperseded(ctx, run, repo, res.Ref, sha, commitStatusInput.Label, secret, reqLogger); err != nil {
+			reqLogger.Error(err, "failed to cancel superseded runs")
+			return reconcile.Result{}, err
+		}
+	}
+	reqLogger.Info("creating a github status for", "resource", res, "status", commitStatusInput, "repo", repo, "sha", res.Ref)
+	posted, err := r.createStatus(ctx, w, res, repo, sha, secret, commitStatusInput, reqLogger)
+	if err != nil {
+		r.recorder.Eventf(run, corev1.EventTypeWarning, reasonStatusFailed, "Failed to report %s status for context %q to %s: %s",
+			commitStatusInput.State, commitStatusInput.Label, repo, err)
+		return reconcile.Result{}, err
+	}
+	reqLogger.Info("created a github status", "sha", posted.SHA, "requestID", posted.RequestID)
+	r.recorder.Eventf(run, corev1.EventTypeNormal, reasonStatusReported, "Reported %s status for context %q to %s",
+		commitStatusInput.State, commitStatusInput.Label, repo)
+
+	patch := client.MergeFrom(run.DeepCopyObject())
+	reported.Annotate(run)
+	posted.Annotate(run)
+	if status == tracker.Pending {
+		tracker.AddFinalizer(run)
+	} else {
+		tracker.RemoveFinalizer(run)
+	}
+	if err := r.client.Patch(ctx, run, patch); err != nil {
+		reqLogger.Error(err, "failed to record the reported status")
+		return reconcile.Result{}, err
+	}
+	r.runs.Set(run.GetUID(), key, status)
+	return reconcile.Result{}, nil
+}
+
+// skip counts a run that was reconciled without reporting a status.
+func (r *Reconciler) skip(reason string) {
+	tracker.RecordSkippedRun(r.kind.Name(), reason)
+}
+
+// lastState returns the state that was last reported for the run's context
+// on the commit, from the store if the annotations on the run are stale.
+func (r *Reconciler) lastState(key string, last *tracker.ReportedStatus, reported tracker.ReportedStatus) (tracker.State, bool) {
+	if s, ok := r.runs.Get(key); ok {
+		return s, true
+	}
+	if last != nil && last.Context == reported.Context && last.Commit == reported.Commit {
+		return last.RunState(), true
+	}
+	return tracker.Pending, false
+}
+
+// keyForRun identifies the status reported by a run for a context on a
+// commit.
+func keyForRun(uid types.UID, context, repo, ref string) string {
+	return sha1String(fmt.Sprintf("%s:%s:%s:%s", uid, context, repo, ref))
+}
+
+// keyForCommit identifies the status for a context on a commit's SHA.
+func keyForCommit(repo, sha, context string) string {
+	return fmt.Sprintf("%s:%s:%s", repo, sha, context)
+}
+
+func sha1String(s string) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(s)))
+}
+
+// pluralName returns the lower-case plural of the Kind's name, e.g.
+// pipelineruns.
+func pluralName(k tracker.Kind) string {
+	return strings.ToLower(k.Name()) + "s"
+}
@@ -0,0 +1,543 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/controller/pipelinerun"
+	"github.com/bigkevmcd/commit-status-tracker/pkg/controller/taskrun"
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+	"github.com/bigkevmcd/commit-status-tracker/test"
+	ctb "github.com/bigkevmcd/commit-status-tracker/test/builder"
+)
+
+var (
+	testNamespace = "test-namespace"
+	testToken     = "abcdefghijklmnopqrstuvwxyz12345678901234"
+	testTime      = time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	testSHA       = "e1466db56110fa1b813277c1647e20283d3370c3"
+)
+
+var _ reconcile.Reconciler = &Reconciler{}
+
+// testKind wraps a Kind with functions to build runs of the kind for the
+// tests, every test is run against each of the testKinds.
+type testKind struct {
+	tracker.Kind
+	makeRun           func(res ...*pipelinev1.PipelineResourceSpec) tracker.Run
+	setStatus         func(r tracker.Run, s corev1.ConditionStatus)
+	setCompletionTime func(r tracker.Run, t time.Time)
+}
+
+var testKinds = []testKind{
+	{
+		Kind: pipelinerun.Kind{},
+		makeRun: func(res ...*pipelinev1.PipelineResourceSpec) tracker.Run {
+			return ctb.MakePipelineRunWithResources(res...)
+		},
+		setStatus: func(r tracker.Run, s corev1.ConditionStatus) {
+			r.(*pipelinev1.PipelineRun).Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: s})
+		},
+		setCompletionTime: func(r tracker.Run, t time.Time) {
+			r.(*pipelinev1.PipelineRun).Status.CompletionTime = &metav1.Time{Time: t}
+		},
+	},
+	{
+		Kind: taskrun.Kind{},
+		makeRun: func(res ...*pipelinev1.PipelineResourceSpec) tracker.Run {
+			return ctb.MakeTaskRunWithInputResources(res...)
+		},
+		setStatus: func(r tracker.Run, s corev1.ConditionStatus) {
+			r.(*pipelinev1.TaskRun).Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: s})
+		},
+		setCompletionTime: func(r tracker.Run, t time.Time) {
+			r.(*pipelinev1.TaskRun).Status.CompletionTime = &metav1.Time{Time: t}
+		},
+	},
+}
+
+func TestReconcileReportsState(t *testing.T) {
+	stateTests := []struct {
+		condition corev1.ConditionStatus
+		want      scm.State
+	}{
+		{corev1.ConditionUnknown, scm.StatePending},
+		{corev1.ConditionTrue, scm.StateSuccess},
+		{corev1.ConditionFalse, scm.StateFailure},
+	}
+
+	forEachKind(t, func(t *testing.T, k testKind) {
+		for _, tt := range stateTests {
+			t.Run(tt.want.String(), func(t *testing.T) {
+				run := k.makeNotifiableRun("test-run", "test-uid", "test-context", tt.condition)
+				r, data := makeReconciler(k, run, makeSecret())
+
+				reconcileRun(t, r, "test-run")
+
+				wanted := &scm.Status{State: tt.want, Label: "test-context", Desc: "testing", Target: ""}
+				assertStatus(t, data, wanted)
+			})
+		}
+	})
+}
+
+// TestReconcileWithPreviousPending tests a run that we've already sent a
+// pending notification for.
+func TestReconcileWithPreviousPending(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+		r, data := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+		// This cleans out the existing date for the data, because the fake scm
+		// client updates in-place, so there's no way to know if it received multiple
+		// pending notifications.
+		delete(data.Statuses, testSHA)
+		reconcileRun(t, r, "test-run")
+
+		// There should be no recorded statuses, because the state is still pending
+		// and the fake client's state was deleted above.
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileNonNotifiable tests a non-notifable run.
+func TestReconcileNonNotifiable(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeRunWith("test-run", corev1.ConditionFalse, nil,
+			ctb.MakeGitResource("https://github.com/tektoncd/triggers", "master"))
+		r, data := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileWithNoGitRepository tests a notifable run with no "git"
+// resource.
+func TestReconcileWithNoGitRepository(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeRunWith("test-run", corev1.ConditionUnknown, notifiableAnnotations("test-context"))
+		r, data := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileWithGitRepositories tests a notifable run with multiple "git"
+// resources.
+func TestReconcileWithGitRepositories(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeRunWith("test-run", corev1.ConditionUnknown, notifiableAnnotations("test-context"),
+			ctb.MakeGitResource("https://github.com/tektoncd/triggers", "master"),
+			ctb.MakeGitResource("https://github.com/tektoncd/pipeline", "master"))
+		r, data := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileWithNoGitCredentials tests a notifable run with a "git"
+// resource, but with no Git credentials.
+func TestReconcileWithNoGitCredentials(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+		r, data := makeReconciler(k, run)
+
+		reconcileRun(t, r, "test-run")
+
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileWithSecretAnnotations tests a notifiable run that names the
+// secret and key that hold its Git credentials.
+func TestReconcileWithSecretAnnotations(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		addAnnotations(run, map[string]string{
+			tracker.StatusSecretName:    "org-secret",
+			tracker.StatusSecretKeyName: "org-token",
+		})
+		secret := ctb.MakeSecret("org-secret", map[string][]byte{"org-token": []byte(testToken)})
+		r, data := makeReconciler(k, run, secret)
+
+		reconcileRun(t, r, "test-run")
+
+		wanted := &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "testing", Target: ""}
+		assertStatus(t, data, wanted)
+	})
+}
+
+// If the run can't be loaded then this isn't an error.
+func TestReconcileMissingRun(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		r, data := makeReconciler(k)
+
+		reconcileRun(t, r, "unknown-run")
+
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// If the run has a bad git repository then this should fail.
+func TestReconcileBadGitRepo(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeRunWith("test-run", corev1.ConditionUnknown, notifiableAnnotations("test-context"),
+			ctb.MakeGitResource("http://192.168.0.%31/test/repo", "master"))
+		r, data := makeReconciler(k, run)
+
+		_, err := r.Reconcile(makeRequest("test-run"))
+		if !test.MatchError(t, "failed to parse repo URL", err) {
+			t.Errorf("unexpected error returned: %s", err)
+		}
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileWithRepoNotAllowed tests a run for a repository that its
+// namespace is not allowed to report statuses for.
+func TestReconcileWithRepoNotAllowed(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		r, data := makeReconciler(k, run, makeSecret())
+		r.options.RepoAllowlist = tracker.RepoAllowlist{testNamespace: {"my-org"}}
+
+		reconcileRun(t, r, "test-run")
+
+		assertNoStatusesRecorded(t, data)
+		assertEvent(t, r, "Warning RepositoryNotAllowed repository tektoncd/triggers is not allowed for runs in namespace test-namespace")
+	})
+}
+
+// TestReconcileWithDashboardURL tests that a run without a target URL is
+// linked to the Tekton Dashboard.
+func TestReconcileWithDashboardURL(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		r, data := makeReconciler(k, run, makeSecret())
+		r.options.DashboardURL = "https://dashboard.example.com"
+
+		reconcileRun(t, r, "test-run")
+
+		wanted := &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "testing",
+			Target: "https://dashboard.example.com/#/namespaces/test-namespace/" + pluralName(k) + "/test-run"}
+		assertStatus(t, data, wanted)
+	})
+}
+
+// TestReconcileRecordsReportedStatus tests that the reported status is
+// recorded in annotations on the run.
+func TestReconcileRecordsReportedStatus(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		r, _ := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		updated := getRun(t, r, "test-run")
+		want := map[string]string{
+			tracker.ReportedStateName:   "success",
+			tracker.ReportedContextName: "test-context",
+			tracker.ReportedCommitName:  "master",
+		}
+		for k, v := range want {
+			if a := updated.GetAnnotations()[k]; a != v {
+				t.Errorf("annotation %s got %#v, want %#v", k, a, v)
+			}
+		}
+	})
+}
+
+// TestReconcileRecordsPostDetails tests that the details of the post, and
+// the reported state, are recorded on the run.
+func TestReconcileRecordsPostDetails(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionFalse)
+		r, _ := makeReconciler(k, run, makeSecret())
+		r.options.DashboardURL = "https://dashboard.example.com"
+
+		reconcileRun(t, r, "test-run")
+
+		updated := getRun(t, r, "test-run")
+		want := map[string]string{
+			tracker.ReportedSHAName:       testSHA,
+			tracker.ReportedTargetURLName: "https://dashboard.example.com/#/namespaces/test-namespace/" + pluralName(k) + "/test-run",
+			tracker.ReportedAtName:        "2020-01-10T12:00:00Z",
+		}
+		for k, v := range want {
+			if a := updated.GetAnnotations()[k]; a != v {
+				t.Errorf("annotation %s got %#v, want %#v", k, a, v)
+			}
+		}
+		if l := updated.GetLabels()[tracker.StateLabel]; l != "failure" {
+			t.Errorf("label %s got %#v, want %#v", tracker.StateLabel, l, "failure")
+		}
+	})
+}
+
+// TestReconcileWithReportedStatus tests a run that has already been reported,
+// by an earlier instance of the operator.
+func TestReconcileWithReportedStatus(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		addAnnotations(run, reportedAnnotations("success"))
+		r, data := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileWithReportedPendingStatus tests a run that has completed since
+// the pending status was reported.
+func TestReconcileWithReportedPendingStatus(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		addAnnotations(run, reportedAnnotations("pending"))
+		r, data := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		wanted := &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "testing", Target: ""}
+		assertStatus(t, data, wanted)
+	})
+}
+
+// TestReconcileRunsWithDifferentContexts tests two runs for the same commit
+// that report different contexts.
+func TestReconcileRunsWithDifferentContexts(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		lint := k.makeNotifiableRun("lint-run", "lint-uid", "lint", corev1.ConditionUnknown)
+		test := k.makeNotifiableRun("test-run", "test-uid", "test", corev1.ConditionUnknown)
+		r, data := makeReconciler(k, lint, test, makeSecret())
+
+		for _, name := range []string{"lint-run", "test-run", "lint-run", "test-run"} {
+			reconcileRun(t, r, name)
+		}
+
+		wanted := []*scm.Status{
+			{State: scm.StatePending, Label: "lint", Desc: "testing", Target: ""},
+			{State: scm.StatePending, Label: "test", Desc: "testing", Target: ""},
+		}
+		if !reflect.DeepEqual(data.Statuses[testSHA], wanted) {
+			t.Fatalf("commit-status notifications got %#v, wanted %#v\n", data.Statuses[testSHA], wanted)
+		}
+	})
+}
+
+// TestReconcileRunsWithSameContext tests a second run for the same commit and
+// context, in the same state, is reported.
+func TestReconcileRunsWithSameContext(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		first := k.makeNotifiableRun("first-run", "first-uid", "test-context", corev1.ConditionUnknown)
+		second := k.makeNotifiableRun("second-run", "second-uid", "test-context", corev1.ConditionUnknown)
+		r, data := makeReconciler(k, first, second, makeSecret())
+
+		reconcileRun(t, r, "first-run")
+		// The fake scm client updates statuses with the same context in-place, so
+		// clear them out to see whether the second run is reported.
+		delete(data.Statuses, testSHA)
+		reconcileRun(t, r, "second-run")
+
+		wanted := &scm.Status{State: scm.StatePending, Label: "test-context", Desc: "testing", Target: ""}
+		if l := len(data.Statuses[testSHA]); l != 1 {
+			t.Fatalf("got %d statuses, wanted 1", l)
+		}
+		assertStatus(t, data, wanted)
+	})
+}
+
+func TestKeyForRun(t *testing.T) {
+	inputTests := []struct {
+		uid     types.UID
+		context string
+		repo    string
+		ref     string
+		want    string
+	}{
+		{"a6b3dd2e-3d1a-11ea-b77f-2e728ce88125", "test-context", "tekton/triggers", "e1466db56110fa1b813277c1647e20283d3370c3",
+			"5aca1e264c5966ed2814158fd70eb649f214ec68"},
+	}
+
+	for _, tt := range inputTests {
+		if v := keyForRun(tt.uid, tt.context, tt.repo, tt.ref); v != tt.want {
+			t.Errorf("keyForRun(%#v, %#v, %#v, %#v) got %#v, want %#v", tt.uid, tt.context, tt.repo, tt.ref, v, tt.want)
+		}
+	}
+}
+
+func forEachKind(t *testing.T, f func(t *testing.T, k testKind)) {
+	for _, k := range testKinds {
+		t.Run(k.Name(), func(t *testing.T) {
+			logf.SetLogger(logf.ZapLogger(true))
+			f(t, k)
+		})
+	}
+}
+
+// makeRunWith returns a run of the kind with the annotations and git
+// resources, and the provided status for the Succeeded condition.
+func (k testKind) makeRunWith(name string, status corev1.ConditionStatus, annotations map[string]string, res ...*pipelinev1.PipelineResourceSpec) tracker.Run {
+	r := k.makeRun(res...)
+	r.SetName(name)
+	r.SetAnnotations(annotations)
+	k.setStatus(r, status)
+	return r
+}
+
+// makeNotifiableRun returns a notifiable run of the kind, for the master
+// commit in tektoncd/triggers, reporting the context.
+func (k testKind) makeNotifiableRun(name string, uid types.UID, context string, status corev1.ConditionStatus) tracker.Run {
+	r := k.makeRunWith(name, status, notifiableAnnotations(context),
+		ctb.MakeGitResource("https://github.com/tektoncd/triggers", "master"))
+	r.SetUID(uid)
+	return r
+}
+
+func notifiableAnnotations(context string) map[string]string {
+	return map[string]string{
+		tracker.NotifiableName:        "true",
+		tracker.StatusContextName:     context,
+		tracker.StatusDescriptionName: "testing",
+	}
+}
+
+func reportedAnnotations(state string) map[string]string {
+	return map[string]string{
+		tracker.ReportedStateName:   state,
+		tracker.ReportedContextName: "test-context",
+		tracker.ReportedCommitName:  "master",
+	}
+}
+
+func addAnnotations(r tracker.Run, annotations map[string]string) {
+	merged := r.GetAnnotations()
+	for k, v := range annotations {
+		merged[k] = v
+	}
+	r.SetAnnotations(merged)
+}
+
+func makeSecret() *corev1.Secret {
+	return ctb.MakeSecret(tracker.SecretName, map[string][]byte{"token": []byte(testToken)})
+}
+
+func makeRequest(name string) reconcile.Request {
+	return reconcile.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      name,
+			Namespace: testNamespace,
+		},
+	}
+}
+
+func makeScheme() *runtime.Scheme {
+	s := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(s))
+	utilruntime.Must(pipelinev1.AddToScheme(s))
+	return s
+}
+
+func makeReconciler(k testKind, objs ...runtime.Object) (*Reconciler, *fakescm.Data) {
+	s := makeScheme()
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	client, data := fakescm.NewDefault()
+	data.Commits["master"] = &scm.Commit{Sha: testSHA}
+	fakeClientFactory := func(s string) *scm.Client {
+		return client
+	}
+	return &Reconciler{
+		kind:     k.Kind,
+		client:   cl,
+		scheme:   s,
+		tokens:   tracker.NewSecretTokenProvider(cl, cl, ""),
+		clients:  tracker.NewClientCache(fakeClientFactory),
+		recorder: record.NewFakeRecorder(100),
+		runs:     tracker.NewStore(pluralName(k), 10, time.Hour),
+		commits:  &tracker.KeyedMutex{},
+		clock:    func() time.Time { return testTime },
+		log:      logf.Log.WithName("controller_test"),
+	}, data
+}
+
+func reconcileRun(t *testing.T, r *Reconciler, name string) {
+	t.Helper()
+	res, err := r.Reconcile(makeRequest(name))
+	fatalIfError(t, err, "reconcile: (%v)", err)
+	if res.Requeue {
+		t.Fatal("reconcile requeued request")
+	}
+}
+
+func getRun(t *testing.T, r *Reconciler, name string) tracker.Run {
+	t.Helper()
+	run := r.kind.New()
+	if err := r.client.Get(context.TODO(), makeRequest(name).NamespacedName, run); err != nil {
+		t.Fatal(err)
+	}
+	return run
+}
+
+func fatalIfError(t *testing.T, err error, format string, a ...interface{}) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf(format, a...)
+	}
+}
+
+func assertStatus(t *testing.T, d *fakescm.Data, want *scm.Status) {
+	t.Helper()
+	if l := len(d.Statuses[testSHA]); l == 0 {
+		t.Fatal("no statuses recorded")
+	}
+	if status := d.Statuses[testSHA][0]; !reflect.DeepEqual(status, want) {
+		t.Fatalf("commit-status notification got %#v, wanted %#v\n", status, want)
+	}
+}
+
+// assertEvent checks that the next event recorded by the Reconciler starts
+// with want.
+func assertEvent(t *testing.T, r *Reconciler, want string) {
+	t.Helper()
+	select {
+	case e := <-r.recorder.(*record.FakeRecorder).Events:
+		if !strings.HasPrefix(e, want) {
+			t.Fatalf("event got %#v, want %#v", e, want)
+		}
+	default:
+		t.Fatalf("no event recorded, want %#v", want)
+	}
+}
+
+func assertNoStatusesRecorded(t *testing.T, d *fakescm.Data) {
+	t.Helper()
+	if l := len(d.Statuses[testSHA]); l != 0 {
+		t.Fatalf("too many statuses recorded, got %v, wanted 0", l)
+	}
+}
@@ -0,0 +1,46 @@
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// listCompleted returns a RunLister that lists the runs of the Kind that
+// completed since the provided time.
+func listCompleted(k tracker.Kind, c client.Client) tracker.RunLister {
+	return func(ctx context.Context, since time.Time) ([]tracker.Trackable, error) {
+		runs, err := k.List(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		completed := []tracker.Trackable{}
+		for _, r := range runs {
+			_, completion := k.Times(r)
+			if completion == nil || completion.Time.Before(since) {
+				continue
+			}
+			completed = append(completed, k.Wrap(r))
+		}
+		return completed, nil
+	}
+}
+
+// isSuperseded implements tracker.SupersededFunc, returning true if a newer
+// run of the Kind reports the same context for the commit.
+func (r *Reconciler) isSuperseded(ctx context.Context, w tracker.Trackable, repo, ref, context string, d scm.Driver) (bool, error) {
+	run := r.kind.New()
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: w.GetNamespace(), Name: w.GetName()}, run); err != nil {
+		return false, err
+	}
+	newer, err := r.newerRun(ctx, run, repo, ref, context, d)
+	if err != nil {
+		return false, err
+	}
+	return newer != nil, nil
+}
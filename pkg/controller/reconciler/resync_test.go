@@ -0,0 +1,58 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListCompleted(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	forEachKind(t, func(t *testing.T, k testKind) {
+		running := k.makeRunWith("running", corev1.ConditionUnknown, nil)
+		old := k.makeRunWith("old", corev1.ConditionTrue, nil)
+		k.setCompletionTime(old, now.Add(-time.Hour*2))
+		recent := k.makeRunWith("recent", corev1.ConditionTrue, nil)
+		k.setCompletionTime(recent, now.Add(-time.Minute*10))
+		cl := fake.NewFakeClientWithScheme(makeScheme(), running, old, recent)
+
+		runs, err := listCompleted(k, cl)(context.TODO(), now.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, r := range runs {
+			names = append(names, r.GetName())
+		}
+		if len(names) != 1 || names[0] != "recent" {
+			t.Fatalf("listCompleted() got %#v, want [recent]", names)
+		}
+	})
+}
+
+func TestIsSuperseded(t *testing.T) {
+	created := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	forEachKind(t, func(t *testing.T, k testKind) {
+		older := k.makeNotifiableRun("older", "older-uid", "test-context", corev1.ConditionFalse)
+		older.SetCreationTimestamp(metav1.NewTime(created))
+		newer := k.makeNotifiableRun("newer", "newer-uid", "test-context", corev1.ConditionTrue)
+		newer.SetCreationTimestamp(metav1.NewTime(created.Add(time.Minute)))
+		r, _ := makeReconciler(k, older, newer)
+
+		for name, want := range map[string]bool{"older": true, "newer": false} {
+			run := getRun(t, r, name)
+			superseded, err := r.isSuperseded(context.TODO(), k.Wrap(run), "tektoncd/triggers", "master", "test-context", scm.DriverFake)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if superseded != want {
+				t.Errorf("isSuperseded(%s) got %v, want %v", name, superseded, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package reconciler
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// listUpdated returns an UpdatedRunLister that lists the notifiable runs of
+// the Kind that were created, started or completed since the provided time.
+//
+// Runs that are waiting for the tracker's Finalizer to be removed are always
+// listed, whenever they were updated, otherwise their deletion would be
+// blocked until the operator next saw an update to them.
+func listUpdated(k tracker.Kind, c client.Client) tracker.UpdatedRunLister {
+	return func(ctx context.Context, since time.Time) ([]tracker.UpdatedRun, error) {
+		runs, err := k.List(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		updated := []tracker.UpdatedRun{}
+		for _, r := range runs {
+			created := r.GetCreationTimestamp()
+			start, completion := k.Times(r)
+			t := tracker.LatestTime(&created, start, completion, r.GetDeletionTimestamp())
+			if !tracker.IsFinalizing(r) && (!tracker.IsNotifiable(k.Wrap(r)) || t.Before(since)) {
+				continue
+			}
+			updated = append(updated, tracker.UpdatedRun{
+				NamespacedName: types.NamespacedName{Namespace: r.GetNamespace(), Name: r.GetName()},
+				Updated:        t,
+			})
+		}
+		return updated, nil
+	}
+}
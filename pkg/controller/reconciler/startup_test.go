@@ -0,0 +1,53 @@
+package reconciler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+func TestListUpdated(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	forEachKind(t, func(t *testing.T, k testKind) {
+		makeCreated := func(name string, created time.Time, annotations map[string]string) tracker.Run {
+			r := k.makeRunWith(name, corev1.ConditionUnknown, annotations)
+			r.SetCreationTimestamp(metav1.NewTime(created))
+			return r
+		}
+		notifiable := notifiableAnnotations("test-context")
+		completed := makeCreated("recently-completed", now.Add(-time.Hour*3), notifiable)
+		k.setCompletionTime(completed, now.Add(-time.Minute*5))
+		deleted := makeCreated("old-deleted", now.Add(-time.Hour*3), notifiable)
+		deleted.SetFinalizers([]string{tracker.Finalizer})
+		deleted.SetDeletionTimestamp(&metav1.Time{Time: now.Add(-time.Hour * 2)})
+		cl := fake.NewFakeClientWithScheme(makeScheme(),
+			makeCreated("not-notifiable", now, nil),
+			makeCreated("old", now.Add(-time.Hour*3), notifiable),
+			makeCreated("recently-created", now.Add(-time.Minute*10), notifiable),
+			completed, deleted)
+
+		runs, err := listUpdated(k, cl)(context.TODO(), now.Add(-time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		updated := map[string]time.Time{}
+		for _, r := range runs {
+			updated[r.Name] = r.Updated.UTC()
+		}
+		want := map[string]time.Time{
+			"recently-created":   now.Add(-time.Minute * 10),
+			"recently-completed": now.Add(-time.Minute * 5),
+			"old-deleted":        now.Add(-time.Hour * 2),
+		}
+		if !reflect.DeepEqual(updated, want) {
+			t.Fatalf("listUpdated() got %#v, want %#v", updated, want)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package reconciler
+
+import (
+	"context"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// newerRun returns a notifiable run of the Kind, in the same namespace, that
+// reports the same context for the commit, and is newer than the run, or nil
+// if the run hasn't been superseded.
+//
+// Runs that are being deleted don't supersede older runs.
+func (r *Reconciler) newerRun(ctx context.Context, run tracker.Run, repo, ref, context string, d scm.Driver) (tracker.Run, error) {
+	runs, err := r.kind.List(ctx, r.client, client.InNamespace(run.GetNamespace()))
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range runs {
+		if other.GetName() == run.GetName() || other.GetDeletionTimestamp() != nil || !tracker.IsNewer(other, run) {
+			continue
+		}
+		w := r.kind.Wrap(other)
+		if !tracker.IsNotifiable(w) {
+			continue
+		}
+		c, err := w.FindCommit()
+		if err != nil || c.Ref != ref {
+			continue
+		}
+		if otherRepo, err := c.Repo(); err != nil || otherRepo != repo {
+			continue
+		}
+		input, err := tracker.GetCommitStatusInput(w, d, r.options.DashboardURL)
+		if err != nil || input.Label != context {
+			continue
+		}
+		return other, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,157 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// reconcileStep updates a run to a state, creating it if necessary, and then
+// reconciles it.
+type reconcileStep struct {
+	run       string
+	condition corev1.ConditionStatus
+}
+
+func TestReconcileSupersededRuns(t *testing.T) {
+	created := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	interleavingTests := []struct {
+		name        string
+		generations map[string]string
+		steps       []reconcileStep
+		want        *scm.Status
+	}{
+		{
+			name: "older run fails after the newer run succeeds",
+			steps: []reconcileStep{
+				{"older", corev1.ConditionUnknown},
+				{"newer", corev1.ConditionUnknown},
+				{"newer", corev1.ConditionTrue},
+				{"older", corev1.ConditionFalse},
+			},
+			want: &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "newer"},
+		},
+		{
+			name: "older run fails before the newer run succeeds",
+			steps: []reconcileStep{
+				{"older", corev1.ConditionUnknown},
+				{"newer", corev1.ConditionUnknown},
+				{"older", corev1.ConditionFalse},
+				{"newer", corev1.ConditionTrue},
+			},
+			want: &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "newer"},
+		},
+		{
+			name: "older run fails while the newer run is pending",
+			steps: []reconcileStep{
+				{"older", corev1.ConditionUnknown},
+				{"newer", corev1.ConditionUnknown},
+				{"older", corev1.ConditionFalse},
+			},
+			want: &scm.Status{State: scm.StatePending, Label: "test-context", Desc: "newer"},
+		},
+		{
+			name: "older run completes before the newer run is created",
+			steps: []reconcileStep{
+				{"older", corev1.ConditionUnknown},
+				{"older", corev1.ConditionTrue},
+				{"newer", corev1.ConditionUnknown},
+			},
+			want: &scm.Status{State: scm.StatePending, Label: "test-context", Desc: "newer"},
+		},
+		{
+			name: "older run is reconciled after the newer run is created",
+			steps: []reconcileStep{
+				{"newer", corev1.ConditionTrue},
+				{"older", corev1.ConditionFalse},
+			},
+			want: &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "newer"},
+		},
+		{
+			name:        "older run with a higher generation",
+			generations: map[string]string{"older": "2", "newer": "1"},
+			steps: []reconcileStep{
+				{"older", corev1.ConditionUnknown},
+				{"newer", corev1.ConditionUnknown},
+				{"older", corev1.ConditionTrue},
+				{"newer", corev1.ConditionFalse},
+			},
+			want: &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "older"},
+		},
+	}
+
+	forEachKind(t, func(t *testing.T, k testKind) {
+		for _, tt := range interleavingTests {
+			t.Run(tt.name, func(t *testing.T) {
+				r, data := makeReconciler(k, makeSecret())
+				createdAt := map[string]time.Time{"older": created, "newer": created.Add(time.Minute)}
+
+				for _, step := range tt.steps {
+					run := k.makeNotifiableRun(step.run, types.UID(step.run), "test-context", step.condition)
+					run.SetCreationTimestamp(metav1.NewTime(createdAt[step.run]))
+					addAnnotations(run, map[string]string{tracker.StatusDescriptionName: step.run})
+					if g, ok := tt.generations[step.run]; ok {
+						addAnnotations(run, map[string]string{tracker.StatusGenerationName: g})
+					}
+					upsertRun(t, r, k, run)
+					reconcileRun(t, r, step.run)
+				}
+
+				if l := len(data.Statuses[testSHA]); l != 1 {
+					t.Fatalf("got %d statuses, want 1", l)
+				}
+				assertStatus(t, data, tt.want)
+			})
+		}
+	})
+}
+
+// TestReconcileDeletedSupersededRun tests that deleting a pending run that has
+// been superseded doesn't report an error for the newer run's context.
+func TestReconcileDeletedSupersededRun(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		created := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+		older := k.makeNotifiableRun("older", "older-uid", "test-context", corev1.ConditionUnknown)
+		older.SetCreationTimestamp(metav1.NewTime(created))
+		addAnnotations(older, reportedAnnotations("pending"))
+		older.SetFinalizers([]string{tracker.Finalizer})
+		older.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+		newer := k.makeNotifiableRun("newer", "newer-uid", "test-context", corev1.ConditionUnknown)
+		newer.SetCreationTimestamp(metav1.NewTime(created.Add(time.Minute)))
+		r, data := makeReconciler(k, older, newer, makeSecret())
+
+		reconcileRun(t, r, "older")
+
+		assertNoStatusesRecorded(t, data)
+		if tracker.HasFinalizer(getRun(t, r, "older")) {
+			t.Fatal("finalizer was not removed from the superseded run")
+		}
+	})
+}
+
+// upsertRun creates the run, or updates the existing run's status and
+// annotations.
+func upsertRun(t *testing.T, r *Reconciler, k testKind, run tracker.Run) {
+	t.Helper()
+	existing := r.kind.New()
+	err := r.client.Get(context.TODO(), makeRequest(run.GetName()).NamespacedName, existing)
+	if err != nil {
+		fatalIfError(t, r.client.Create(context.TODO(), run), "create: (%v)", err)
+		return
+	}
+	succeeded, _ := k.Conditions(run)
+	k.setStatus(existing, succeeded.Status)
+	annotations := existing.GetAnnotations()
+	for key, v := range run.GetAnnotations() {
+		annotations[key] = v
+	}
+	existing.SetAnnotations(annotations)
+	fatalIfError(t, r.client.Update(context.TODO(), existing), "update: (%v)", err)
+}
@@ -0,0 +1,81 @@
+package reconciler
+
+import (
+	"context"
+	"net/url"
+
+	"go.opencensus.io/trace"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// These are the names of the spans that are created when reconciling a run,
+// the requests to the Git hosting service are traced by the SCM client.
+const (
+	spanReconcile  = "commit-status-tracker/Reconcile"
+	spanFindCommit = "commit-status-tracker/FindCommit"
+	spanToken      = "commit-status-tracker/Token"
+)
+
+// traceReconcile reconciles the run in a span, with the run as attributes.
+func (r *Reconciler) traceReconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	ctx, span := trace.StartSpan(ctx, spanReconcile)
+	defer span.End()
+	span.AddAttributes(
+		trace.StringAttribute("kind", r.kind.Name()),
+		trace.StringAttribute("namespace", request.Namespace),
+		trace.StringAttribute("name", request.Name))
+	res, err := r.reconcile(ctx, request)
+	if err != nil {
+		span.SetStatus(errorStatus(err))
+	}
+	return res, err
+}
+
+// findCommit finds the commit for the run in a span, and adds the commit to
+// the attributes of the parent span.
+//
+// Repository URLs can include credentials, so only the host and path of the
+// URL are recorded.
+func (r *Reconciler) findCommit(ctx context.Context, w tracker.Trackable) (*tracker.Commit, error) {
+	_, span := trace.StartSpan(ctx, spanFindCommit)
+	defer span.End()
+	c, err := w.FindCommit()
+	if err != nil {
+		span.SetStatus(errorStatus(err))
+		return nil, err
+	}
+	attrs := []trace.Attribute{trace.StringAttribute("repo_url", redactURL(c.RepoURL)), trace.StringAttribute("sha", c.Ref)}
+	span.AddAttributes(attrs...)
+	trace.FromContext(ctx).AddAttributes(attrs...)
+	return c, nil
+}
+
+// token gets the token for the run's commit in a span.
+func (r *Reconciler) token(ctx context.Context, w tracker.Trackable, c *tracker.Commit) (string, error) {
+	ctx, span := trace.StartSpan(ctx, spanToken)
+	defer span.End()
+	token, err := r.tokens.Token(ctx, w, c)
+	if err != nil {
+		span.SetStatus(errorStatus(err))
+	}
+	return token, err
+}
+
+// redactURL removes the userinfo, query and fragment from the URL, URLs that
+// can't be parsed are redacted completely.
+func redactURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "redacted"
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+func errorStatus(err error) trace.Status {
+	return trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()}
+}
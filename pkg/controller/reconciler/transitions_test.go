@@ -0,0 +1,72 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/controller/taskrun"
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+)
+
+// TestReconcileStalePendingRun tests that a completed run that is seen as
+// pending again, e.g. from a stale cache, doesn't report the pending status.
+func TestReconcileStalePendingRun(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionTrue)
+		r, data := makeReconciler(k, run, makeSecret())
+		reconcileRun(t, r, "test-run")
+
+		stale := getRun(t, r, "test-run")
+		k.setStatus(stale, corev1.ConditionUnknown)
+		stale.SetAnnotations(notifiableAnnotations("test-context"))
+		err := r.client.Update(context.TODO(), stale)
+		fatalIfError(t, err, "update: (%v)", err)
+		reconcileRun(t, r, "test-run")
+
+		wanted := &scm.Status{State: scm.StateSuccess, Label: "test-context", Desc: "testing", Target: ""}
+		assertStatus(t, data, wanted)
+	})
+}
+
+// TestReconcileWithReportedCompletedStatus tests a pending run that was
+// reported as completed by an earlier instance of the operator.
+func TestReconcileWithReportedCompletedStatus(t *testing.T) {
+	forEachKind(t, func(t *testing.T, k testKind) {
+		run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionUnknown)
+		addAnnotations(run, reportedAnnotations("failure"))
+		r, data := makeReconciler(k, run, makeSecret())
+
+		reconcileRun(t, r, "test-run")
+
+		assertNoStatusesRecorded(t, data)
+	})
+}
+
+// TestReconcileRetriedRun tests that a TaskRun that is retried after it failed
+// reports the pending status for the retry.
+func TestReconcileRetriedRun(t *testing.T) {
+	k := testKinds[1]
+	if _, ok := k.Kind.(taskrun.Kind); !ok {
+		t.Fatalf("test kind %s is not a TaskRun", k.Name())
+	}
+	run := k.makeNotifiableRun("test-run", "test-uid", "test-context", corev1.ConditionFalse)
+	r, data := makeReconciler(k, run, makeSecret())
+	reconcileRun(t, r, "test-run")
+
+	retried := getRun(t, r, "test-run").(*pipelinev1.TaskRun)
+	retried.Status.RetriesStatus = append(retried.Status.RetriesStatus, retried.Status)
+	k.setStatus(retried, corev1.ConditionUnknown)
+	err := r.client.Update(context.TODO(), retried)
+	fatalIfError(t, err, "update: (%v)", err)
+	reconcileRun(t, r, "test-run")
+
+	wanted := &scm.Status{State: scm.StatePending, Label: "test-context", Desc: "testing", Target: ""}
+	assertStatus(t, data, wanted)
+	if a := getRun(t, r, "test-run").GetAnnotations()[tracker.ReportedRetriesName]; a != "1" {
+		t.Fatalf("reported retries got %#v, want %#v", a, "1")
+	}
+}
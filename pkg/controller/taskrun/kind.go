@@ -0,0 +1,67 @@
+package taskrun
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+)
+
+// Kind adapts TaskRuns for tracking.
+type Kind struct{}
+
+var _ tracker.Kind = Kind{}
+
+// Name implements tracker.Kind.
+func (Kind) Name() string {
+	return "TaskRun"
+}
+
+// New implements tracker.Kind.
+func (Kind) New() tracker.Run {
+	return &pipelinev1.TaskRun{}
+}
+
+// List implements tracker.Kind.
+func (Kind) List(ctx context.Context, c client.Client, opts ...client.ListOption) ([]tracker.Run, error) {
+	runs := &pipelinev1.TaskRunList{}
+	if err := c.List(ctx, runs, opts...); err != nil {
+		return nil, err
+	}
+	items := make([]tracker.Run, len(runs.Items))
+	for i := range runs.Items {
+		items[i] = &runs.Items[i]
+	}
+	return items, nil
+}
+
+// Wrap implements tracker.Kind.
+func (Kind) Wrap(r tracker.Run) tracker.Trackable {
+	return wrap(r.(*pipelinev1.TaskRun))
+}
+
+// Times implements tracker.Kind.
+func (Kind) Times(r tracker.Run) (*metav1.Time, *metav1.Time) {
+	tr := r.(*pipelinev1.TaskRun)
+	return tr.Status.StartTime, tr.Status.CompletionTime
+}
+
+// Conditions implements tracker.Kind, TaskRuns have no children.
+func (Kind) Conditions(r tracker.Run) (*apis.Condition, map[string]*apis.Condition) {
+	return r.(*pipelinev1.TaskRun).Status.GetCondition(apis.ConditionSucceeded), nil
+}
+
+// Retries implements tracker.Kind.
+func (Kind) Retries(r tracker.Run) int {
+	return len(r.(*pipelinev1.TaskRun).Status.RetriesStatus)
+}
+
+// Cancel implements tracker.Kind, TaskRuns are not cancelled when superseded,
+// as they're usually part of a PipelineRun.
+func (Kind) Cancel(r tracker.Run) bool {
+	return false
+}
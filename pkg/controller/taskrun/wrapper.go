@@ -5,6 +5,10 @@ import (
 	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 )
 
+// Tekton labels TaskRuns that are part of a PipelineRun with the name of the
+// Pipeline.
+const pipelineLabel = "tekton.dev/pipeline"
+
 type taskRunWrapper struct {
 	*pipelinev1.TaskRun
 }
@@ -29,6 +33,35 @@ func (t taskRunWrapper) FindCommit() (*tracker.Commit, error) {
 	return tracker.FindCommit(extractPipelineResources(t.Spec.Inputs.Resources))
 }
 
+// RunDetails returns the details of the TaskRun for templating.
+func (t taskRunWrapper) RunDetails() tracker.RunDetails {
+	state := t.RunState()
+	failed := []string{}
+	if state == tracker.Failed {
+		failed = append(failed, t.taskName())
+	}
+	return tracker.RunDetails{
+		Kind:               "TaskRun",
+		Name:               t.Name,
+		Namespace:          t.Namespace,
+		PipelineName:       t.Labels[pipelineLabel],
+		Labels:             t.Labels,
+		Params:             tracker.ParamsToMap(t.Spec.Inputs.Params),
+		Duration:           tracker.RunDuration(t.Status.StartTime, t.Status.CompletionTime),
+		FailedTasks:        failed,
+		Failure:            tracker.TaskRunFailure(t.taskName(), &t.Status),
+		State:              state,
+		ServiceAccountName: t.Spec.ServiceAccountName,
+	}
+}
+
+func (t taskRunWrapper) taskName() string {
+	if t.Spec.TaskRef != nil {
+		return t.Spec.TaskRef.Name
+	}
+	return t.Name
+}
+
 func extractPipelineResources(bindings []pipelinev1.TaskResourceBinding) []*pipelinev1.PipelineResourceSpec {
 	resources := make([]*pipelinev1.PipelineResourceSpec, len(bindings))
 	for i, b := range bindings {
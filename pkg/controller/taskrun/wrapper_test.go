@@ -3,6 +3,11 @@ package taskrun
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	ttb "github.com/tektoncd/pipeline/test/builder"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
 
 	"github.com/bigkevmcd/commit-status-tracker/pkg/tracker"
 	tb "github.com/bigkevmcd/commit-status-tracker/test/builder"
@@ -32,3 +37,49 @@ func TestRunState(t *testing.T) {
 func TestAnnotations(t *testing.T) {
 	t.Skip()
 }
+
+func TestRunDetails(t *testing.T) {
+	start := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	detailsTests := []struct {
+		name   string
+		status corev1.ConditionStatus
+		opts   []ttb.TaskRunOp
+		want   tracker.RunDetails
+	}{
+		{"successful task run", corev1.ConditionTrue, nil,
+			tracker.RunDetails{FailedTasks: []string{}, State: tracker.Successful}},
+		{"failed task run with task ref", corev1.ConditionFalse,
+			[]ttb.TaskRunOp{ttb.TaskRunSpec(ttb.TaskRunTaskRef("lint-task"))},
+			tracker.RunDetails{FailedTasks: []string{"lint-task"}, Failure: "lint-task failed", State: tracker.Failed}},
+		{"failed task run without task ref", corev1.ConditionFalse, nil,
+			tracker.RunDetails{FailedTasks: []string{"test-task-run"}, Failure: "test-task-run failed", State: tracker.Failed}},
+		{"task run in a pipeline", corev1.ConditionUnknown,
+			[]ttb.TaskRunOp{ttb.TaskRunLabel(pipelineLabel, "test-pipeline")},
+			tracker.RunDetails{PipelineName: "test-pipeline", FailedTasks: []string{}, State: tracker.Pending}},
+	}
+
+	for _, tt := range detailsTests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]ttb.TaskRunOp{
+				ttb.TaskRunSpec(ttb.TaskRunInputs(ttb.TaskRunInputsParam("environment", "staging"))),
+				ttb.TaskRunLabel("app", "my-app"),
+				ttb.TaskRunStatus(
+					ttb.StatusCondition(apis.Condition{Type: apis.ConditionSucceeded, Status: tt.status}),
+					ttb.TaskRunStartTime(start),
+					ttb.TaskRunCompletionTime(start.Add(time.Minute*3)),
+				)}, tt.opts...)
+			taskRun := ttb.TaskRun("test-task-run", "test-namespace", opts...)
+
+			want := tt.want
+			want.Kind = "TaskRun"
+			want.Name = "test-task-run"
+			want.Namespace = "test-namespace"
+			want.Labels = taskRun.Labels
+			want.Params = map[string]string{"environment": "staging"}
+			want.Duration = time.Minute * 3
+			if d := wrap(taskRun).RunDetails(); !reflect.DeepEqual(d, want) {
+				t.Fatalf("RunDetails() got %#v, want %#v", d, want)
+			}
+		})
+	}
+}
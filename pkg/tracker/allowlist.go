@@ -0,0 +1,93 @@
+package tracker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AnyNamespace is the key in a RepoAllowlist for the repositories allowed in
+// namespaces that aren't listed.
+const AnyNamespace = "*"
+
+// RepoAllowlist is the repositories that runs in each namespace can report
+// statuses for, keyed by namespace.
+//
+// Each entry is a repository owner, e.g. "tektoncd", or a glob for the full
+// name of the repository, e.g. "tektoncd/*-catalog".
+//
+// A nil RepoAllowlist allows all repositories.
+type RepoAllowlist map[string][]string
+
+// LoadRepoAllowlist reads a RepoAllowlist from a YAML file, e.g.
+//
+//	team-a:
+//	  - tektoncd
+//	  - my-org/team-a-*
+//	"*":
+//	  - my-org/shared
+func LoadRepoAllowlist(filename string) (RepoAllowlist, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository allowlist: %w", err)
+	}
+	a := RepoAllowlist{}
+	if err := yaml.Unmarshal(b, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse repository allowlist %s: %w", filename, err)
+	}
+	for ns, entries := range a {
+		for _, e := range entries {
+			if _, err := path.Match(e, ""); err != nil {
+				return nil, fmt.Errorf("failed to parse repository allowlist %s: invalid entry %#v for namespace %s: %w", filename, e, ns, err)
+			}
+		}
+	}
+	return a, nil
+}
+
+// Allowed returns true if runs in the namespace can report statuses for the
+// repository, which is the full name, e.g. "tektoncd/pipeline".
+func (a RepoAllowlist) Allowed(namespace, repo string) bool {
+	if a == nil {
+		return true
+	}
+	entries, ok := a[namespace]
+	if !ok {
+		entries = a[AnyNamespace]
+	}
+	repo = strings.ToLower(repo)
+	for _, e := range entries {
+		e = strings.ToLower(e)
+		if !strings.Contains(e, "/") {
+			e += "/*"
+		}
+		if ok, _ := path.Match(e, repo); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// RepoNotAllowedError is returned when a run's namespace is not allowed to
+// report statuses for the repository.
+type RepoNotAllowedError struct {
+	Namespace string
+	Repo      string
+}
+
+func (e RepoNotAllowedError) Error() string {
+	return fmt.Sprintf("repository %s is not allowed for runs in namespace %s", e.Repo, e.Namespace)
+}
+
+// CheckRepo returns a RepoNotAllowedError, and counts the denial, if the
+// namespace is not allowed to report statuses for the repository.
+func (a RepoAllowlist) CheckRepo(namespace, repo string) error {
+	if a.Allowed(namespace, repo) {
+		return nil
+	}
+	deniedRepos.WithLabelValues(namespace).Inc()
+	return RepoNotAllowedError{Namespace: namespace, Repo: repo}
+}
@@ -0,0 +1,101 @@
+package tracker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+)
+
+func TestRepoAllowlistAllowed(t *testing.T) {
+	allowlist := RepoAllowlist{
+		"team-a":     {"tektoncd", "my-org/team-a-*"},
+		"team-b":     {},
+		AnyNamespace: {"my-org/shared"},
+	}
+	allowedTests := []struct {
+		allowlist RepoAllowlist
+		namespace string
+		repo      string
+		want      bool
+	}{
+		{nil, "team-a", "other/repo", true},
+		{allowlist, "team-a", "tektoncd/pipeline", true},
+		{allowlist, "team-a", "TektonCD/Pipeline", true},
+		{allowlist, "team-a", "my-org/team-a-service", true},
+		{allowlist, "team-a", "my-org/team-b-service", false},
+		{allowlist, "team-a", "my-org/shared", false},
+		{allowlist, "team-b", "tektoncd/pipeline", false},
+		{allowlist, "team-c", "my-org/shared", true},
+		{allowlist, "team-c", "tektoncd/pipeline", false},
+		{RepoAllowlist{"team-a": {"tektoncd"}}, "team-c", "tektoncd/pipeline", false},
+	}
+
+	for _, tt := range allowedTests {
+		if b := tt.allowlist.Allowed(tt.namespace, tt.repo); b != tt.want {
+			t.Errorf("Allowed(%#v, %#v) got %v, want %v", tt.namespace, tt.repo, b, tt.want)
+		}
+	}
+}
+
+func TestRepoAllowlistCheckRepo(t *testing.T) {
+	allowlist := RepoAllowlist{"team-a": {"tektoncd"}}
+	counter := deniedRepos.WithLabelValues("team-a")
+	before := testutil.ToFloat64(counter)
+
+	if err := allowlist.CheckRepo("team-a", "tektoncd/pipeline"); err != nil {
+		t.Fatal(err)
+	}
+	err := allowlist.CheckRepo("team-a", "other/repo")
+	if !test.MatchError(t, "repository other/repo is not allowed for runs in namespace team-a", err) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if v := testutil.ToFloat64(counter); v != before+1 {
+		t.Fatalf("denied repositories got %v, want %v", v, before+1)
+	}
+}
+
+func TestLoadRepoAllowlist(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "allowlist.yaml")
+	writeFile(t, filename, "team-a:\n  - tektoncd\n  - my-org/team-a-*\n\"*\":\n  - my-org/shared\n")
+
+	a, err := LoadRepoAllowlist(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := RepoAllowlist{"team-a": {"tektoncd", "my-org/team-a-*"}, "*": {"my-org/shared"}}
+	if !reflect.DeepEqual(a, want) {
+		t.Fatalf("LoadRepoAllowlist() got %#v, want %#v", a, want)
+	}
+}
+
+func TestLoadRepoAllowlistErrors(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	writeFile(t, filepath.Join(dir, "invalid.yaml"), "team-a: tektoncd\n")
+	writeFile(t, filepath.Join(dir, "bad-glob.yaml"), "team-a:\n  - \"my-org/[\"\n")
+	errorTests := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{"missing file", "missing.yaml", "failed to read repository allowlist"},
+		{"invalid file", "invalid.yaml", "failed to parse repository allowlist"},
+		{"invalid glob", "bad-glob.yaml", "invalid entry \"my-org/\\[\" for namespace team-a"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRepoAllowlist(filepath.Join(dir, tt.file))
+			if !test.MatchError(t, tt.wantErr, err) {
+				t.Fatalf("failed to match error: got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
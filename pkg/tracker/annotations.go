@@ -7,4 +7,40 @@ const (
 
 	// TODO: This could also come from a ConfigMap based on the context.
 	StatusDescriptionName = "tekton.dev/status-description"
+
+	// StatusGenerationName orders runs for the same context on a commit,
+	// runs with a higher generation supersede runs with a lower one.
+	StatusGenerationName = "tekton.dev/status-generation"
+
+	// StatusBranchName identifies the branch or pull request that a commit was
+	// run for, pending runs for older commits on the same branch can be
+	// cancelled when a newer commit is run.
+	StatusBranchName = "tekton.dev/status-branch"
+
+	// StatusSecretName and StatusSecretKeyName choose the Secret in the run's
+	// namespace, and the key in it, that holds the token for the Git hosting
+	// service.
+	StatusSecretName    = "tekton.dev/status-secret"
+	StatusSecretKeyName = "tekton.dev/status-secret-key"
+)
+
+// These annotations are written by the tracker to record the last status that
+// was reported for a run.
+const (
+	ReportedStateName   = "commit-status.tekton.dev/reported-state"
+	ReportedContextName = "commit-status.tekton.dev/reported-context"
+	ReportedCommitName  = "commit-status.tekton.dev/reported-commit"
+	ReportedRetriesName = "commit-status.tekton.dev/reported-retries"
+
+	// These record the details of the last post to the Git hosting service,
+	// they are not used to decide whether to report a status.
+	ReportedSHAName       = "commit-status.tekton.dev/reported-sha"
+	ReportedTargetURLName = "commit-status.tekton.dev/reported-target-url"
+	ReportedRequestIDName = "commit-status.tekton.dev/reported-request-id"
+	ReportedAtName        = "commit-status.tekton.dev/reported-at"
 )
+
+// StateLabel is written by the tracker with the last state that was reported
+// for a run, so that runs can be selected by their state, e.g.
+// commit-status.tekton.dev/state=failure.
+const StateLabel = "commit-status.tekton.dev/state"
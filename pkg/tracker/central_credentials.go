@@ -0,0 +1,126 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Central credentials are Secrets in the operator's namespace, labelled with
+// CentralCredentialLabel, that runs can use if their namespace has no Secret
+// of its own.
+//
+// The annotations on each Secret are the policy for which namespaces can use
+// it, and for which repository owners.
+const (
+	CentralCredentialLabel = "commit-status.tekton.dev/credential"
+
+	// CredentialOwnersName is a comma-separated list of the repository owners
+	// that the credential can report statuses for.
+	CredentialOwnersName = "commit-status.tekton.dev/owners"
+
+	// CredentialNamespacesName is a comma-separated list of the namespaces
+	// that can use the credential.
+	CredentialNamespacesName = "commit-status.tekton.dev/namespaces"
+
+	// CredentialNamespaceSelectorName is a label selector for namespaces that
+	// can use the credential, e.g. "team in (a, b)".
+	CredentialNamespaceSelectorName = "commit-status.tekton.dev/namespace-selector"
+)
+
+var credentialsLog = logf.Log.WithName("credentials")
+
+// CentralCredentials identifies the namespace that holds central
+// credentials, and the reader that they're read with.
+//
+// The credentials namespace is usually outside the namespace that the
+// operator's cache watches, and namespaces are cluster-scoped, so the reader
+// should not be cached, e.g. the Manager's APIReader.
+type CentralCredentials struct {
+	Namespace string
+	Reader    client.Reader
+}
+
+// NewCentralCredentials returns the CentralCredentials for the namespace,
+// or nil if the namespace is empty, which disables central credentials.
+func NewCentralCredentials(reader client.Reader, ns string) *CentralCredentials {
+	if ns == "" {
+		return nil
+	}
+	return &CentralCredentials{Namespace: ns, Reader: reader}
+}
+
+// centralToken returns the token from the first central credential, in name
+// order, that the namespace can use for the repository owner.
+//
+// If no central credential allows it, the denial is logged and an error is
+// returned.
+func centralToken(c client.Reader, credentialsNS, ns, owner string) (string, error) {
+	secrets := &corev1.SecretList{}
+	err := c.List(context.TODO(), secrets, client.InNamespace(credentialsNS), client.MatchingLabels{CentralCredentialLabel: "true"})
+	if err != nil {
+		return "", fmt.Errorf("failed to GetAuthSecret, error listing central credentials in namespace '%s': %w", credentialsNS, err)
+	}
+	sort.Slice(secrets.Items, func(i, j int) bool {
+		return secrets.Items[i].Name < secrets.Items[j].Name
+	})
+	var nsLabels labels.Set
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		if !listContains(s.Annotations[CredentialOwnersName], owner) {
+			continue
+		}
+		if listContains(s.Annotations[CredentialNamespacesName], ns) {
+			return secretToken(s), nil
+		}
+		// An empty selector matches every namespace, so it's treated as
+		// if it wasn't set.
+		selector := strings.TrimSpace(s.Annotations[CredentialNamespaceSelectorName])
+		if selector == "" {
+			continue
+		}
+		sel, err := labels.Parse(selector)
+		if err != nil {
+			credentialsLog.Error(err, "invalid namespace selector for central credential", "secret", s.Name)
+			continue
+		}
+		if nsLabels == nil {
+			nsLabels, err = namespaceLabels(c, ns)
+			if err != nil {
+				return "", fmt.Errorf("failed to GetAuthSecret, error getting namespace '%s': %w", ns, err)
+			}
+		}
+		if sel.Matches(nsLabels) {
+			return secretToken(s), nil
+		}
+	}
+	credentialsLog.Info("namespace is not allowed to use any central credential for the repository owner",
+		"namespace", ns, "owner", owner, "credentialsNamespace", credentialsNS)
+	return "", fmt.Errorf("failed to GetAuthSecret, namespace '%s' has no secret '%s' and is not allowed to use a central credential for '%s'", ns, SecretName, owner)
+}
+
+func namespaceLabels(c client.Reader, name string) (labels.Set, error) {
+	ns := &corev1.Namespace{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name}, ns); err != nil {
+		return nil, err
+	}
+	return labels.Set(ns.Labels), nil
+}
+
+// listContains returns true if the comma-separated list contains the value,
+// ignoring case.
+func listContains(list, v string) bool {
+	for _, s := range strings.Split(list, ",") {
+		if v != "" && strings.EqualFold(strings.TrimSpace(s), v) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package tracker
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+	tb "github.com/bigkevmcd/commit-status-tracker/test/builder"
+)
+
+func TestGetAuthSecretForRunWithCentralCredentials(t *testing.T) {
+	credentialTests := []struct {
+		name string
+		objs []runtime.Object
+		want string
+	}{
+		{"allowed by namespace name", []runtime.Object{
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:     "tektoncd",
+				CredentialNamespacesName: "other-namespace, test-namespace",
+			}),
+		}, "central-token"},
+		{"allowed by namespace selector", []runtime.Object{
+			makeNamespace("test-namespace", map[string]string{"team": "ci"}),
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:            "other,TektonCD",
+				CredentialNamespaceSelectorName: "team in (ci, release)",
+			}),
+		}, "central-token"},
+		{"first allowed credential", []runtime.Object{
+			makeCentralCredential("a-bot", "other-token", map[string]string{
+				CredentialOwnersName:     "other",
+				CredentialNamespacesName: "test-namespace",
+			}),
+			makeCentralCredential("b-bot", "central-token", map[string]string{
+				CredentialOwnersName:     "tektoncd",
+				CredentialNamespacesName: "test-namespace",
+			}),
+		}, "central-token"},
+		{"namespace secret", []runtime.Object{
+			tb.MakeSecret(SecretName, map[string][]byte{"token": []byte(testToken)}),
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:     "tektoncd",
+				CredentialNamespacesName: "test-namespace",
+			}),
+		}, testToken},
+	}
+
+	for _, tt := range credentialTests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fake.NewFakeClient(tt.objs...)
+			run := fakeObject{details: RunDetails{Namespace: "test-namespace"}}
+
+			sec, err := GetAuthSecretForRun(cl, run, testCommit, NewCentralCredentials(cl, "operators"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sec != tt.want {
+				t.Fatalf("got %s, want %s", sec, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAuthSecretForRunWithDeniedCentralCredentials(t *testing.T) {
+	denialTests := []struct {
+		name          string
+		credentialsNS string
+		objs          []runtime.Object
+		wantErr       string
+	}{
+		{"other owner", "operators", []runtime.Object{
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:     "other",
+				CredentialNamespacesName: "test-namespace",
+			}),
+		}, "namespace 'test-namespace' has no secret 'commit-status-tracker-git-secret' and is not allowed to use a central credential for 'tektoncd'"},
+		{"other namespace", "operators", []runtime.Object{
+			makeNamespace("test-namespace", map[string]string{"team": "dev"}),
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:            "tektoncd",
+				CredentialNamespacesName:        "other-namespace",
+				CredentialNamespaceSelectorName: "team=ci",
+			}),
+		}, "is not allowed to use a central credential for 'tektoncd'"},
+		{"empty namespace selector", "operators", []runtime.Object{
+			makeNamespace("test-namespace", map[string]string{"team": "ci"}),
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:            "tektoncd",
+				CredentialNamespaceSelectorName: "",
+			}),
+		}, "is not allowed to use a central credential for 'tektoncd'"},
+		{"blank namespace selector", "operators", []runtime.Object{
+			makeNamespace("test-namespace", map[string]string{"team": "ci"}),
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:            "tektoncd",
+				CredentialNamespaceSelectorName: "  ",
+			}),
+		}, "is not allowed to use a central credential for 'tektoncd'"},
+		{"central credentials disabled", "", []runtime.Object{
+			makeCentralCredential("bot", "central-token", map[string]string{
+				CredentialOwnersName:     "tektoncd",
+				CredentialNamespacesName: "test-namespace",
+			}),
+		}, "error getting secret 'commit-status-tracker-git-secret' in namespace 'test-namespace':.* not found"},
+	}
+
+	for _, tt := range denialTests {
+		t.Run(tt.name, func(t *testing.T) {
+			cl := fake.NewFakeClient(tt.objs...)
+			run := fakeObject{details: RunDetails{Namespace: "test-namespace"}}
+
+			_, err := GetAuthSecretForRun(cl, run, testCommit, NewCentralCredentials(cl, tt.credentialsNS))
+			if !test.MatchError(t, tt.wantErr, err) {
+				t.Fatalf("failed to match error: got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func makeCentralCredential(name, token string, annotations map[string]string) *corev1.Secret {
+	return &corev1.Secret{
+		Type: corev1.SecretTypeOpaque,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "operators",
+			Labels:      map[string]string{CentralCredentialLabel: "true"},
+			Annotations: annotations,
+		},
+		Data: map[string][]byte{"token": []byte(token)},
+	}
+}
+
+func makeNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
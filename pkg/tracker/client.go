@@ -1,7 +1,7 @@
 package tracker
 
 import (
-	"context"
+	"net/http"
 
 	"github.com/jenkins-x/go-scm/scm"
 	"github.com/jenkins-x/go-scm/scm/driver/github"
@@ -15,12 +15,21 @@ import (
 // create the correct scm.Client.
 type SCMClientFactory func(string) *scm.Client
 
+// The latency of the client's requests is recorded in the
+// commit_status_tracker_scm_request_duration_seconds metric, and they are
+// traced.
+//
 // TODO: fix this to determine the type of scm Client to create.
 func CreateSCMClient(token string) *scm.Client {
 	client := github.NewDefault()
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	client.Client = oauth2.NewClient(context.Background(), ts)
+	client.Client = &http.Client{
+		Transport: &oauth2.Transport{
+			Source: ts,
+			Base:   tracingTransport(instrumentedTransport{base: http.DefaultTransport}),
+		},
+	}
 	return client
 }
@@ -0,0 +1,136 @@
+package tracker
+
+import (
+	"container/list"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+// clientCacheSize is the maximum number of clients that a ClientCache holds.
+const clientCacheSize = 100
+
+// ClientCache holds the SCM clients created for tokens, so that connections
+// to the Git hosting service are reused between reconciles.
+//
+// At most clientCacheSize clients are held, the least recently used client
+// is discarded when it's full, so the clients for tokens that were rotated
+// are discarded eventually, even if they're never forgotten.
+type ClientCache struct {
+	factory SCMClientFactory
+	size    int
+
+	mu      sync.Mutex
+	clients *list.List
+	tokens  map[string]*list.Element
+}
+
+type cachedClient struct {
+	token  string
+	client *scm.Client
+}
+
+// NewClientCache creates a ClientCache that creates clients with the factory.
+func NewClientCache(f SCMClientFactory) *ClientCache {
+	return &ClientCache{factory: f, size: clientCacheSize, clients: list.New(), tokens: make(map[string]*list.Element)}
+}
+
+// Client returns the client for the token, creating it if necessary, it
+// can be used as an SCMClientFactory.
+func (c *ClientCache) Client(token string) *scm.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.tokens[token]; ok {
+		c.clients.MoveToFront(el)
+		return el.Value.(*cachedClient).client
+	}
+	client := c.factory(token)
+	c.tokens[token] = c.clients.PushFront(&cachedClient{token: token, client: client})
+	for c.clients.Len() > c.size {
+		c.remove(c.clients.Back())
+	}
+	return client
+}
+
+// Forget discards the client for the token.
+func (c *ClientCache) Forget(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.tokens[token]; ok {
+		c.remove(el)
+	}
+}
+
+// Purge discards all the clients.
+func (c *ClientCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clients.Init()
+	c.tokens = make(map[string]*list.Element)
+}
+
+// Len returns the number of clients held.
+func (c *ClientCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clients.Len()
+}
+
+func (c *ClientCache) remove(el *list.Element) {
+	c.clients.Remove(el)
+	delete(c.tokens, el.Value.(*cachedClient).token)
+}
+
+// PurgeOnSecretChange returns an event handler for a Secret informer that
+// discards the clients for the tokens in a Secret when its data changes, or
+// it's deleted, so that rotated tokens are used.
+//
+// Tokens are read from the values in a Secret's data, so only the clients for
+// those values are discarded, changes to Secrets that don't hold tokens, e.g.
+// service account tokens, don't discard any clients.
+func PurgeOnSecretChange(c *ClientCache) toolscache.ResourceEventHandler {
+	forget := func(s *corev1.Secret) {
+		for _, v := range s.Data {
+			c.Forget(string(v))
+		}
+	}
+	return toolscache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			o, ok := oldObj.(*corev1.Secret)
+			n, nok := newObj.(*corev1.Secret)
+			if !ok || !nok {
+				c.Purge()
+				return
+			}
+			if reflect.DeepEqual(o.Data, n.Data) {
+				return
+			}
+			forget(o)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			s, ok := obj.(*corev1.Secret)
+			if !ok {
+				c.Purge()
+				return
+			}
+			forget(s)
+		},
+	}
+}
+
+// IsUnauthorized returns true if the Git hosting service rejected the
+// credentials for a request.
+func IsUnauthorized(res *scm.Response, err error) bool {
+	if errors.Is(err, scm.ErrNotAuthorized) {
+		return true
+	}
+	return err != nil && res != nil && res.Status == http.StatusUnauthorized
+}
@@ -0,0 +1,130 @@
+package tracker
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	corev1 "k8s.io/api/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+)
+
+func TestClientCache(t *testing.T) {
+	created := 0
+	c := NewClientCache(func(token string) *scm.Client {
+		created++
+		return &scm.Client{}
+	})
+
+	first := c.Client("token-1")
+	if c.Client("token-1") != first {
+		t.Fatal("client for the same token was not reused")
+	}
+	c.Client("token-2")
+	if created != 2 {
+		t.Fatalf("got %d clients created, want 2", created)
+	}
+
+	c.Forget("token-1")
+	if c.Client("token-1") == first {
+		t.Fatal("client for a forgotten token was reused")
+	}
+	c.Purge()
+	if l := c.Len(); l != 0 {
+		t.Fatalf("got %d clients after purging, want 0", l)
+	}
+}
+
+func TestClientCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewClientCache(func(token string) *scm.Client {
+		return &scm.Client{}
+	})
+	c.size = 2
+
+	first := c.Client("token-1")
+	c.Client("token-2")
+	c.Client("token-1")
+	c.Client("token-3")
+
+	if l := c.Len(); l != 2 {
+		t.Fatalf("got %d clients, want 2", l)
+	}
+	if c.Client("token-1") != first {
+		t.Fatal("recently used client was evicted")
+	}
+	if _, ok := c.tokens["token-2"]; ok {
+		t.Fatal("least recently used client was not evicted")
+	}
+}
+
+func TestPurgeOnSecretChange(t *testing.T) {
+	makeSecret := func(token string) *corev1.Secret {
+		return &corev1.Secret{Data: map[string][]byte{"token": []byte(token)}}
+	}
+	c := NewClientCache(func(token string) *scm.Client {
+		return &scm.Client{}
+	})
+	h := PurgeOnSecretChange(c)
+
+	c.Client("token")
+	h.OnUpdate(makeSecret("token"), makeSecret("token"))
+	if l := c.Len(); l != 1 {
+		t.Fatalf("got %d clients after a resync, want 1", l)
+	}
+	h.OnUpdate(makeSecret("token"), makeSecret("rotated"))
+	if l := c.Len(); l != 0 {
+		t.Fatalf("got %d clients after the secret changed, want 0", l)
+	}
+	c.Client("token")
+	h.OnDelete(makeSecret("token"))
+	if l := c.Len(); l != 0 {
+		t.Fatalf("got %d clients after the secret was deleted, want 0", l)
+	}
+}
+
+func TestPurgeOnSecretChangeIgnoresOtherSecrets(t *testing.T) {
+	makeSecret := func(token string) *corev1.Secret {
+		return &corev1.Secret{Data: map[string][]byte{"token": []byte(token)}}
+	}
+	c := NewClientCache(func(token string) *scm.Client {
+		return &scm.Client{}
+	})
+	h := PurgeOnSecretChange(c)
+	c.Client("token")
+	c.Client("other-token")
+
+	h.OnUpdate(makeSecret("sa-token"), makeSecret("rotated-sa-token"))
+	h.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "test-namespace/release", Obj: makeSecret("release")})
+	if l := c.Len(); l != 2 {
+		t.Fatalf("got %d clients after other secrets changed, want 2", l)
+	}
+	h.OnDelete(toolscache.DeletedFinalStateUnknown{Key: "test-namespace/secret", Obj: makeSecret("token")})
+	if l := c.Len(); l != 1 {
+		t.Fatalf("got %d clients after the secret was deleted, want 1", l)
+	}
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	unauthorizedTests := []struct {
+		name string
+		res  *scm.Response
+		err  error
+		want bool
+	}{
+		{"no error", &scm.Response{Status: http.StatusCreated}, nil, false},
+		{"unauthorized", &scm.Response{Status: http.StatusUnauthorized}, errors.New("Bad credentials"), true},
+		{"not authorized error", nil, scm.ErrNotAuthorized, true},
+		{"wrapped not authorized error", nil, fmt.Errorf("failed to resolve ref 'master' in tektoncd/triggers: %w", scm.ErrNotAuthorized), true},
+		{"other error", &scm.Response{Status: http.StatusNotFound}, errors.New("Not Found"), false},
+	}
+
+	for _, tt := range unauthorizedTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b := IsUnauthorized(tt.res, tt.err); b != tt.want {
+				t.Fatalf("IsUnauthorized() got %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
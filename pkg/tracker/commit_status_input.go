@@ -8,15 +8,39 @@ import (
 // returns a status record for submitting to the upstream Git Hosting
 // Service.
 //
+// The context, description and target URL annotations are executed as
+// text/templates with the RunDetails of the run, and the results are truncated
+// to fit the limits of the driver.
+//
+// If there is no description annotation, failed runs are described with the
+// reason for the failure.
+//
+// If there is no target URL annotation, and a dashboardURL is provided, the
+// target URL will link to the run in the Tekton Dashboard.
+//
 // See https://developer.github.com/v3/repos/statuses/#create-a-status and
 // https://github.com/jenkins-x/go-scm/blob/b48d209334ed7b167bad3326a481ae3964c7c1a1/scm/repo.go#L88
-func GetCommitStatusInput(r trackableResource) *scm.StatusInput {
+func GetCommitStatusInput(r trackableResource, d scm.Driver, dashboardURL string) (*scm.StatusInput, error) {
+	details := r.RunDetails()
+	label, err := renderAnnotation(r, StatusContextName, "default", details)
+	if err != nil {
+		return nil, err
+	}
+	desc, err := renderAnnotation(r, StatusDescriptionName, details.Failure, details)
+	if err != nil {
+		return nil, err
+	}
+	target, err := renderAnnotation(r, StatusTargetURLName, DashboardURL(dashboardURL, details), details)
+	if err != nil {
+		return nil, err
+	}
+	limits := limitsForDriver(d)
 	return &scm.StatusInput{
 		State:  convertState(r.RunState()),
-		Label:  getAnnotationByName(r, StatusContextName, "default"),
-		Desc:   getAnnotationByName(r, StatusDescriptionName, ""),
-		Target: getAnnotationByName(r, StatusTargetURLName, ""),
-	}
+		Label:  truncate(label, limits.context),
+		Desc:   truncateWithEllipsis(desc, limits.description),
+		Target: truncate(target, limits.targetURL),
+	}, nil
 }
 
 func getAnnotationByName(r trackableResource, name, def string) string {
@@ -1,7 +1,14 @@
 package tracker
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
 )
 
 func TestAnnotationByName(t *testing.T) {
@@ -23,8 +30,196 @@ func TestAnnotationByName(t *testing.T) {
 	}
 }
 
+func TestGetCommitStatusInput(t *testing.T) {
+	details := RunDetails{
+		Name:         "test-pipeline-run",
+		Namespace:    "test-ns",
+		PipelineName: "test-pipeline",
+		Labels:       map[string]string{"app": "my-app"},
+		Params:       map[string]string{"environment": "staging"},
+		Duration:     time.Minute * 2,
+		FailedTasks:  []string{"lint", "test"},
+		State:        Failed,
+	}
+	inputTests := []struct {
+		name        string
+		annotations map[string]string
+		want        *scm.StatusInput
+	}{
+		{"no annotations", map[string]string{},
+			&scm.StatusInput{State: scm.StateFailure, Label: "default"}},
+		{"plain annotations", map[string]string{StatusContextName: "test-lint", StatusDescriptionName: "linting", StatusTargetURLName: "https://example.com/"},
+			&scm.StatusInput{State: scm.StateFailure, Label: "test-lint", Desc: "linting", Target: "https://example.com/"}},
+		{"templated context", map[string]string{StatusContextName: "{{ .PipelineName }}/{{ .Params.environment }}"},
+			&scm.StatusInput{State: scm.StateFailure, Label: "test-pipeline/staging"}},
+		{"templated description", map[string]string{StatusDescriptionName: `{{ .State }} after {{ .Duration }}: {{ join .FailedTasks ", " }}`},
+			&scm.StatusInput{State: scm.StateFailure, Label: "default", Desc: "Failed after 2m0s: lint, test"}},
+		{"templated target url", map[string]string{StatusTargetURLName: `https://example.com/{{ .Namespace }}/{{ .Name }}?app={{ index .Labels "app" }}`},
+			&scm.StatusInput{State: scm.StateFailure, Label: "default", Target: "https://example.com/test-ns/test-pipeline-run?app=my-app"}},
+		{"missing label", map[string]string{StatusContextName: `ci/{{ index .Labels "unknown" }}`},
+			&scm.StatusInput{State: scm.StateFailure, Label: "ci/"}},
+	}
+
+	for _, tt := range inputTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := fakeObject{annotations: tt.annotations, details: details}
+			s, err := GetCommitStatusInput(r, scm.DriverGithub, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s, tt.want) {
+				t.Errorf("GetCommitStatusInput() got %#v, want %#v", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCommitStatusInputWithFailure(t *testing.T) {
+	failure := "build failed in step compile: " + strings.Repeat("error ", 30)
+	details := RunDetails{Failure: failure, State: Failed}
+	failureTests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{"no description annotation", map[string]string{}, failure[:137] + "..."},
+		{"with description annotation", map[string]string{StatusDescriptionName: "testing"}, "testing"},
+	}
+
+	for _, tt := range failureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := fakeObject{annotations: tt.annotations, details: details}
+			s, err := GetCommitStatusInput(r, scm.DriverGithub, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Desc != tt.want {
+				t.Errorf("GetCommitStatusInput() got description %#v, want %#v", s.Desc, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCommitStatusInputWithDashboardURL(t *testing.T) {
+	details := RunDetails{Kind: "PipelineRun", Name: "test-pipeline-run", Namespace: "test-ns"}
+	dashboardTests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{"no target url annotation", map[string]string{},
+			"https://dashboard.example.com/#/namespaces/test-ns/pipelineruns/test-pipeline-run"},
+		{"with target url annotation", map[string]string{StatusTargetURLName: "https://example.com/"},
+			"https://example.com/"},
+	}
+
+	for _, tt := range dashboardTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := fakeObject{annotations: tt.annotations, details: details}
+			s, err := GetCommitStatusInput(r, scm.DriverGithub, "https://dashboard.example.com/")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Target != tt.want {
+				t.Errorf("GetCommitStatusInput() got target %#v, want %#v", s.Target, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCommitStatusInputWithInvalidTemplate(t *testing.T) {
+	errorTests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     string
+	}{
+		{"unparseable context", map[string]string{StatusContextName: "{{ .Name "}, "failed to parse annotation tekton.dev/status-context"},
+		{"unknown field in description", map[string]string{StatusDescriptionName: "{{ .Unknown }}"}, "failed to execute annotation tekton.dev/status-description"},
+		{"unparseable target url", map[string]string{StatusTargetURLName: "{{ end }}"}, "failed to parse annotation tekton.dev/status-target-url"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := fakeObject{annotations: tt.annotations}
+			_, err := GetCommitStatusInput(r, scm.DriverGithub, "")
+			if !test.MatchError(t, tt.wantErr, err) {
+				t.Errorf("GetCommitStatusInput() got error %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetCommitStatusInputTruncates(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	r := fakeObject{annotations: map[string]string{
+		StatusContextName:     long,
+		StatusDescriptionName: long,
+		StatusTargetURLName:   long,
+	}}
+	limitTests := []struct {
+		driver     scm.Driver
+		context    int
+		desc       int
+		target     int
+		descSuffix string
+	}{
+		{scm.DriverGithub, 255, 140, 255, "..."},
+		{scm.DriverGitlab, 255, 255, 255, "..."},
+		{scm.DriverBitbucket, 40, 255, 255, "..."},
+		{scm.DriverStash, 255, 255, 300, "..."},
+		{scm.DriverFake, 255, 140, 255, "..."},
+	}
+
+	for _, tt := range limitTests {
+		t.Run(tt.driver.String(), func(t *testing.T) {
+			s, err := GetCommitStatusInput(r, tt.driver, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l := len(s.Label); l != tt.context {
+				t.Errorf("got context length %d, want %d", l, tt.context)
+			}
+			if l := len(s.Desc); l != tt.desc {
+				t.Errorf("got description length %d, want %d", l, tt.desc)
+			}
+			if !strings.HasSuffix(s.Desc, tt.descSuffix) {
+				t.Errorf("got description %#v, want suffix %#v", s.Desc, tt.descSuffix)
+			}
+			if l := len(s.Target); l != tt.target {
+				t.Errorf("got target length %d, want %d", l, tt.target)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	truncateTests := []struct {
+		s            string
+		n            int
+		want         string
+		wantEllipsis string
+	}{
+		{"testing", 10, "testing", "testing"},
+		{"testing", 7, "testing", "testing"},
+		{"testing", 6, "testin", "tes..."},
+		{"testing", 2, "te", "te"},
+		{"héllo wörld", 5, "héllo", "hé..."},
+	}
+
+	for _, tt := range truncateTests {
+		if v := truncate(tt.s, tt.n); v != tt.want {
+			t.Errorf("truncate(%#v, %d) got %#v, want %#v", tt.s, tt.n, v, tt.want)
+		}
+		if v := truncateWithEllipsis(tt.s, tt.n); v != tt.wantEllipsis {
+			t.Errorf("truncateWithEllipsis(%#v, %d) got %#v, want %#v", tt.s, tt.n, v, tt.wantEllipsis)
+		}
+	}
+}
+
 type fakeObject struct {
 	annotations map[string]string
+	details     RunDetails
+	commit      *Commit
 }
 
 func (fo fakeObject) Annotations() map[string]string {
@@ -32,9 +227,21 @@ func (fo fakeObject) Annotations() map[string]string {
 }
 
 func (fo fakeObject) RunState() State {
-	return Pending
+	return fo.details.State
 }
 
 func (fo fakeObject) FindCommit() (*Commit, error) {
-	return nil, nil
+	return fo.commit, nil
+}
+
+func (fo fakeObject) RunDetails() RunDetails {
+	return fo.details
+}
+
+func (fo fakeObject) GetNamespace() string {
+	return fo.details.Namespace
+}
+
+func (fo fakeObject) GetName() string {
+	return fo.details.Name
 }
@@ -0,0 +1,18 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DashboardURL returns the URL for the run in a Tekton Dashboard running at
+// base.
+//
+// If base is empty, no URL is returned.
+func DashboardURL(base string, d RunDetails) string {
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/#/namespaces/%s/%ss/%s",
+		strings.TrimSuffix(base, "/"), d.Namespace, strings.ToLower(d.Kind), d.Name)
+}
@@ -0,0 +1,30 @@
+package tracker
+
+import (
+	"testing"
+)
+
+func TestDashboardURL(t *testing.T) {
+	urlTests := []struct {
+		name    string
+		base    string
+		details RunDetails
+		want    string
+	}{
+		{"no base url", "", RunDetails{Kind: "PipelineRun", Name: "test-run", Namespace: "test-ns"}, ""},
+		{"pipeline run", "https://dashboard.example.com", RunDetails{Kind: "PipelineRun", Name: "test-run", Namespace: "test-ns"},
+			"https://dashboard.example.com/#/namespaces/test-ns/pipelineruns/test-run"},
+		{"task run", "https://dashboard.example.com", RunDetails{Kind: "TaskRun", Name: "test-run", Namespace: "test-ns"},
+			"https://dashboard.example.com/#/namespaces/test-ns/taskruns/test-run"},
+		{"base url with trailing slash", "https://example.com/dashboard/", RunDetails{Kind: "PipelineRun", Name: "test-run", Namespace: "test-ns"},
+			"https://example.com/dashboard/#/namespaces/test-ns/pipelineruns/test-run"},
+	}
+
+	for _, tt := range urlTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if u := DashboardURL(tt.base, tt.details); u != tt.want {
+				t.Errorf("DashboardURL() got %#v, want %#v", u, tt.want)
+			}
+		})
+	}
+}
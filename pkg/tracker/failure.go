@@ -0,0 +1,70 @@
+package tracker
+
+import (
+	"fmt"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+const oomKilled = "OOMKilled"
+
+// TaskRunFailure returns a description of why a TaskRun failed, identifying
+// the step that failed, and why.
+//
+// An empty string is returned if the TaskRun did not fail.
+func TaskRunFailure(taskName string, status *pipelinev1.TaskRunStatus) string {
+	if status == nil || ConditionsToState(status.Conditions) != Failed {
+		return ""
+	}
+	step, detail := failedStep(status.Steps)
+	if detail != oomKilled {
+		if msg := conditionMessage(status.Conditions); msg != "" {
+			detail = msg
+		}
+	}
+	switch {
+	case step != "" && detail != "":
+		return fmt.Sprintf("%s failed in step %s: %s", taskName, step, detail)
+	case detail != "":
+		return fmt.Sprintf("%s failed: %s", taskName, detail)
+	default:
+		return fmt.Sprintf("%s failed", taskName)
+	}
+}
+
+// ConditionsFailure returns the message from a failed ConditionSucceeded.
+//
+// An empty string is returned if the conditions don't indicate a failure.
+func ConditionsFailure(conditions duckv1.Conditions) string {
+	if ConditionsToState(conditions) != Failed {
+		return ""
+	}
+	return conditionMessage(conditions)
+}
+
+// failedStep returns the name of the first step that terminated with a
+// non-zero exit code, and a description of how it terminated.
+func failedStep(steps []pipelinev1.StepState) (string, string) {
+	for _, s := range steps {
+		t := s.Terminated
+		if t == nil || t.ExitCode == 0 {
+			continue
+		}
+		if t.Reason == oomKilled {
+			return s.Name, oomKilled
+		}
+		return s.Name, fmt.Sprintf("exit code %d", t.ExitCode)
+	}
+	return "", ""
+}
+
+func conditionMessage(conditions duckv1.Conditions) string {
+	for _, c := range conditions {
+		if c.Type == apis.ConditionSucceeded {
+			return c.Message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package tracker
+
+import (
+	"testing"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1beta1"
+)
+
+func TestTaskRunFailure(t *testing.T) {
+	failureTests := []struct {
+		name   string
+		status *pipelinev1.TaskRunStatus
+		want   string
+	}{
+		{"no status", nil, ""},
+		{"successful task", taskRunStatus(corev1.ConditionTrue, ""), ""},
+		{"pending task", taskRunStatus(corev1.ConditionUnknown, ""), ""},
+		{"failed task with no details", taskRunStatus(corev1.ConditionFalse, ""), "build failed"},
+		{"failed task with message", taskRunStatus(corev1.ConditionFalse, "TaskRun timed out"),
+			"build failed: TaskRun timed out"},
+		{"failed step with exit code", taskRunStatus(corev1.ConditionFalse, "",
+			step("fetch", 0, ""), step("compile", 2, "Error")),
+			"build failed in step compile: exit code 2"},
+		{"failed step with message", taskRunStatus(corev1.ConditionFalse, `"step-compile" exited with code 2`,
+			step("compile", 2, "Error")),
+			`build failed in step compile: "step-compile" exited with code 2`},
+		{"oom killed step", taskRunStatus(corev1.ConditionFalse, `"step-compile" exited with code 137`,
+			step("compile", 137, "OOMKilled"), step("publish", 1, "Error")),
+			"build failed in step compile: OOMKilled"},
+	}
+
+	for _, tt := range failureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if f := TaskRunFailure("build", tt.status); f != tt.want {
+				t.Errorf("TaskRunFailure() got %#v, want %#v", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionsFailure(t *testing.T) {
+	failureTests := []struct {
+		name string
+		c    duckv1.Conditions
+		want string
+	}{
+		{"successful run", duckv1.Conditions{apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue, Message: "All Tasks have completed executing"}}, ""},
+		{"failed run", duckv1.Conditions{apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Message: "PipelineRun timed out"}}, "PipelineRun timed out"},
+	}
+
+	for _, tt := range failureTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if f := ConditionsFailure(tt.c); f != tt.want {
+				t.Errorf("ConditionsFailure() got %#v, want %#v", f, tt.want)
+			}
+		})
+	}
+}
+
+func taskRunStatus(s corev1.ConditionStatus, msg string, steps ...pipelinev1.StepState) *pipelinev1.TaskRunStatus {
+	return &pipelinev1.TaskRunStatus{
+		Status: duckv1.Status{
+			Conditions: duckv1.Conditions{
+				apis.Condition{Type: apis.ConditionSucceeded, Status: s, Message: msg},
+			},
+		},
+		TaskRunStatusFields: pipelinev1.TaskRunStatusFields{
+			Steps: steps,
+		},
+	}
+}
+
+func step(name string, exitCode int32, reason string) pipelinev1.StepState {
+	return pipelinev1.StepState{
+		Name: name,
+		ContainerState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: reason},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package tracker
+
+import (
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Finalizer is added to notifiable runs while they're pending, so that a
+// terminal status can be reported if the run is deleted before it completes.
+const Finalizer = "commit-status.tekton.dev/finalizer"
+
+const deletedDescription = "run was deleted before completion"
+
+// HasFinalizer returns true if the object has the tracker's Finalizer.
+func HasFinalizer(o metav1.Object) bool {
+	for _, f := range o.GetFinalizers() {
+		if f == Finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// AddFinalizer adds the tracker's Finalizer to the object.
+func AddFinalizer(o metav1.Object) {
+	if HasFinalizer(o) {
+		return
+	}
+	o.SetFinalizers(append(o.GetFinalizers(), Finalizer))
+}
+
+// RemoveFinalizer removes the tracker's Finalizer from the object.
+func RemoveFinalizer(o metav1.Object) {
+	finalizers := []string{}
+	for _, f := range o.GetFinalizers() {
+		if f != Finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	o.SetFinalizers(finalizers)
+}
+
+// IsFinalizing returns true if the object has been deleted, and is waiting
+// for the tracker's Finalizer to be removed.
+func IsFinalizing(o metav1.Object) bool {
+	return o.GetDeletionTimestamp() != nil && HasFinalizer(o)
+}
+
+// FinalizerExpired returns true if the object has been waiting to be deleted
+// for longer than the timeout.
+func FinalizerExpired(o metav1.Object, timeout time.Duration, now time.Time) bool {
+	ts := o.GetDeletionTimestamp()
+	return ts != nil && now.Sub(ts.Time) > timeout
+}
+
+// DeletedStatusInput returns the status to report for a context, when a run
+// is deleted before it completes.
+func DeletedStatusInput(context string) *scm.StatusInput {
+	return &scm.StatusInput{
+		State: scm.StateError,
+		Label: context,
+		Desc:  deletedDescription,
+	}
+}
@@ -0,0 +1,79 @@
+package tracker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tb "github.com/tektoncd/pipeline/test/builder"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFinalizers(t *testing.T) {
+	pr := tb.PipelineRun("test-pipeline-run", "foo")
+	pr.Finalizers = []string{"example.com/other"}
+
+	if HasFinalizer(pr) {
+		t.Fatal("HasFinalizer() got true, want false")
+	}
+	AddFinalizer(pr)
+	AddFinalizer(pr)
+	if want := []string{"example.com/other", Finalizer}; !reflect.DeepEqual(pr.Finalizers, want) {
+		t.Fatalf("AddFinalizer() got %#v, want %#v", pr.Finalizers, want)
+	}
+	if !HasFinalizer(pr) {
+		t.Fatal("HasFinalizer() got false, want true")
+	}
+	RemoveFinalizer(pr)
+	if want := []string{"example.com/other"}; !reflect.DeepEqual(pr.Finalizers, want) {
+		t.Fatalf("RemoveFinalizer() got %#v, want %#v", pr.Finalizers, want)
+	}
+}
+
+func TestFinalizerExpired(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	expiryTests := []struct {
+		name    string
+		deleted *metav1.Time
+		want    bool
+	}{
+		{"not deleted", nil, false},
+		{"recently deleted", &metav1.Time{Time: now.Add(-time.Minute)}, false},
+		{"deleted before the timeout", &metav1.Time{Time: now.Add(-time.Minute * 10)}, true},
+	}
+
+	for _, tt := range expiryTests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := tb.PipelineRun("test-pipeline-run", "foo")
+			pr.DeletionTimestamp = tt.deleted
+			if b := FinalizerExpired(pr, time.Minute*5, now); b != tt.want {
+				t.Errorf("FinalizerExpired() got %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsFinalizing(t *testing.T) {
+	deleted := &metav1.Time{Time: time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)}
+	finalizingTests := []struct {
+		name       string
+		deleted    *metav1.Time
+		finalizers []string
+		want       bool
+	}{
+		{"not deleted", nil, []string{Finalizer}, false},
+		{"deleted without the finalizer", deleted, []string{"example.com/other"}, false},
+		{"deleted with the finalizer", deleted, []string{Finalizer}, true},
+	}
+
+	for _, tt := range finalizingTests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := tb.PipelineRun("test-pipeline-run", "foo")
+			pr.DeletionTimestamp = tt.deleted
+			pr.Finalizers = tt.finalizers
+			if b := IsFinalizing(pr); b != tt.want {
+				t.Errorf("IsFinalizing() got %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package tracker
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// gitHostAnnotationPrefix prefixes the annotations that Tekton uses to match
+// git credentials to hosts, e.g. "tekton.dev/git-0: https://github.com".
+const gitHostAnnotationPrefix = "tekton.dev/git-"
+
+const defaultServiceAccountName = "default"
+
+// serviceAccountToken returns the token from the first of the secrets linked
+// to the service account that Tekton would use to clone from the host, or ""
+// if there is no service account, or none of its secrets match.
+//
+// The password is used for basic-auth secrets, and the "token" key for other
+// secrets.
+func serviceAccountToken(c client.Reader, ns, name, host string) (string, error) {
+	if name == "" {
+		name = defaultServiceAccountName
+	}
+	sa := &corev1.ServiceAccount{}
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: name}, sa)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, ref := range sa.Secrets {
+		secret := &corev1.Secret{}
+		err := c.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: ref.Name}, secret)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		if !matchesGitHost(secret, host) {
+			continue
+		}
+		if token := secretToken(secret); token != "" {
+			return token, nil
+		}
+	}
+	return "", nil
+}
+
+// matchesGitHost returns true if the secret has a Tekton git annotation for
+// the host.
+func matchesGitHost(s *corev1.Secret, host string) bool {
+	for k, v := range s.Annotations {
+		if strings.HasPrefix(k, gitHostAnnotationPrefix) && annotatedHost(v) == host {
+			return true
+		}
+	}
+	return false
+}
+
+// annotatedHost returns the host from an annotation value, which is usually a
+// URL, but can be a bare host.
+func annotatedHost(v string) string {
+	if u, err := url.Parse(v); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return v
+}
+
+func secretToken(s *corev1.Secret) string {
+	if s.Type == corev1.SecretTypeBasicAuth {
+		return string(s.Data[corev1.BasicAuthPasswordKey])
+	}
+	return string(s.Data[secretID])
+}
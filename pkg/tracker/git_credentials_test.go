@@ -0,0 +1,133 @@
+package tracker
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+	tb "github.com/bigkevmcd/commit-status-tracker/test/builder"
+)
+
+func TestGetAuthSecretForRunWithServiceAccount(t *testing.T) {
+	credentialTests := []struct {
+		name           string
+		serviceAccount string
+		secret         bool
+		objs           []runtime.Object
+		want           string
+	}{
+		{"basic-auth secret for the host", "build-bot", false, []runtime.Object{
+			makeServiceAccount("build-bot", "github-basic"),
+			makeGitSecret("github-basic", corev1.SecretTypeBasicAuth, "https://github.com",
+				map[string][]byte{"username": []byte("bot"), "password": []byte("basic-token")}),
+		}, "basic-token"},
+		{"token secret for the host", "build-bot", false, []runtime.Object{
+			makeServiceAccount("build-bot", "github-token"),
+			makeGitSecret("github-token", corev1.SecretTypeOpaque, "github.com",
+				map[string][]byte{"token": []byte("opaque-token")}),
+		}, "opaque-token"},
+		{"default service account", "", false, []runtime.Object{
+			makeServiceAccount("default", "github-token"),
+			makeGitSecret("github-token", corev1.SecretTypeOpaque, "https://github.com",
+				map[string][]byte{"token": []byte("default-token")}),
+		}, "default-token"},
+		{"first matching secret", "build-bot", false, []runtime.Object{
+			makeServiceAccount("build-bot", "missing", "gitlab-token", "github-token"),
+			makeGitSecret("gitlab-token", corev1.SecretTypeOpaque, "https://gitlab.com",
+				map[string][]byte{"token": []byte("gitlab-token")}),
+			makeGitSecret("github-token", corev1.SecretTypeOpaque, "https://github.com",
+				map[string][]byte{"token": []byte("github-token")}),
+		}, "github-token"},
+		{"dedicated secret and a secret for the host", "build-bot", true, []runtime.Object{
+			makeServiceAccount("build-bot", "github-token"),
+			makeGitSecret("github-token", corev1.SecretTypeOpaque, "https://github.com",
+				map[string][]byte{"token": []byte("clone-token")}),
+		}, testToken},
+		{"secret for another host", "build-bot", true, []runtime.Object{
+			makeServiceAccount("build-bot", "gitlab-token"),
+			makeGitSecret("gitlab-token", corev1.SecretTypeOpaque, "https://gitlab.com",
+				map[string][]byte{"token": []byte("gitlab-token")}),
+		}, testToken},
+		{"no service account", "build-bot", true, []runtime.Object{}, testToken},
+	}
+
+	for _, tt := range credentialTests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := tt.objs
+			if tt.secret {
+				objs = append(objs, tb.MakeSecret(SecretName, map[string][]byte{"token": []byte(testToken)}))
+			}
+			cl := fake.NewFakeClient(objs...)
+			run := fakeObject{details: RunDetails{Namespace: "test-namespace", ServiceAccountName: tt.serviceAccount}}
+
+			sec, err := GetAuthSecretForRun(cl, run, testCommit, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sec != tt.want {
+				t.Fatalf("got %s, want %s", sec, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAuthSecretForRunWithNoCredentials(t *testing.T) {
+	cl := fake.NewFakeClient(
+		makeServiceAccount("default", "gitlab-token"),
+		makeGitSecret("gitlab-token", corev1.SecretTypeOpaque, "https://gitlab.com",
+			map[string][]byte{"token": []byte("gitlab-token")}))
+	run := fakeObject{details: RunDetails{Namespace: "test-namespace"}}
+
+	_, err := GetAuthSecretForRun(cl, run, testCommit, nil)
+
+	if !test.MatchError(t, "error getting secret 'commit-status-tracker-git-secret' in namespace 'test-namespace'", err) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestGetAuthSecretForRunPrefersAnnotations(t *testing.T) {
+	cl := fake.NewFakeClient(
+		makeServiceAccount("default", "github-token"),
+		makeGitSecret("github-token", corev1.SecretTypeOpaque, "https://github.com",
+			map[string][]byte{"token": []byte("github-token")}),
+		tb.MakeSecret("org-secret", map[string][]byte{"token": []byte("org-token")}),
+	)
+	run := fakeObject{
+		annotations: map[string]string{StatusSecretName: "org-secret"},
+		details:     RunDetails{Namespace: "test-namespace"},
+	}
+
+	sec, err := GetAuthSecretForRun(cl, run, testCommit, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sec != "org-token" {
+		t.Fatalf("got %s, want %s", sec, "org-token")
+	}
+}
+
+func makeServiceAccount(name string, secrets ...string) *corev1.ServiceAccount {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-namespace"},
+	}
+	for _, s := range secrets {
+		sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: s})
+	}
+	return sa
+}
+
+func makeGitSecret(name string, secretType corev1.SecretType, host string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		Type: secretType,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "test-namespace",
+			Annotations: map[string]string{"tekton.dev/git-0": host},
+		},
+		Data: data,
+	}
+}
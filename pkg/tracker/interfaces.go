@@ -16,8 +16,15 @@ type gitRefFinder interface {
 	FindCommit() (*Commit, error)
 }
 
+// runDetailsGetter returns the details of a run for use in templated
+// annotations.
+type runDetailsGetter interface {
+	RunDetails() RunDetails
+}
+
 type trackableResource interface {
 	stateGetter
 	annotationsGetter
 	gitRefFinder
+	runDetailsGetter
 }
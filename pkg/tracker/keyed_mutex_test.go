@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestKeyedMutexSerialisesKeys(t *testing.T) {
+	var k KeyedMutex
+	var wg sync.WaitGroup
+	inFlight := map[string]int{}
+	var mu sync.Mutex
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i%5)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := k.Lock(key)
+			defer unlock()
+
+			mu.Lock()
+			inFlight[key]++
+			if n := inFlight[key]; n > 1 {
+				t.Errorf("%d holders of the lock for %s", n, key)
+			}
+			mu.Unlock()
+
+			mu.Lock()
+			inFlight[key]--
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if l := k.Len(); l != 0 {
+		t.Fatalf("got %d locks after unlocking, want 0", l)
+	}
+}
+
+func TestKeyedMutexDoesNotBlockOtherKeys(t *testing.T) {
+	var k KeyedMutex
+	unlock := k.Lock("first")
+	defer unlock()
+
+	done := make(chan struct{})
+	go func() {
+		k.Lock("second")()
+		close(done)
+	}()
+	<-done
+	if l := k.Len(); l != 1 {
+		t.Fatalf("got %d locks, want 1", l)
+	}
+}
@@ -0,0 +1,59 @@
+package tracker
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Run is a run of any Kind, e.g. a PipelineRun.
+type Run interface {
+	runtime.Object
+	metav1.Object
+}
+
+// Trackable is a run that has been wrapped by its Kind, so that its status
+// can be tracked.
+type Trackable interface {
+	trackableResource
+	GetNamespace() string
+	GetName() string
+}
+
+// Kind adapts a kind of run for tracking, new kinds of run can be tracked by
+// implementing a Kind.
+type Kind interface {
+	// Name is the name of the kind, e.g. PipelineRun.
+	Name() string
+
+	// New returns an empty run of this kind to fetch into.
+	New() Run
+
+	// List returns the runs of this kind, all of them unless the options
+	// restrict them, e.g. to a namespace.
+	List(ctx context.Context, c client.Client, opts ...client.ListOption) ([]Run, error)
+
+	// Wrap wraps a run of this kind so that it can be tracked.
+	Wrap(r Run) Trackable
+
+	// Times returns when a run of this kind started and completed, either
+	// can be nil if the run hasn't started or completed.
+	Times(r Run) (start, completion *metav1.Time)
+
+	// Conditions returns the Succeeded condition of a run of this kind, and
+	// the Succeeded conditions of its child runs, keyed by name, either can
+	// be nil.
+	Conditions(r Run) (succeeded *apis.Condition, children map[string]*apis.Condition)
+
+	// Retries returns the number of times a run of this kind has been
+	// retried, a retried run is pending again after it has completed.
+	Retries(r Run) int
+
+	// Cancel marks a run of this kind as cancelled, it returns false if runs
+	// of this kind aren't cancelled when superseded, or the run has already
+	// been cancelled.
+	Cancel(r Run) bool
+}
@@ -0,0 +1,52 @@
+package tracker
+
+import (
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+// statusLimits are the maximum lengths (in characters) that a Git hosting
+// service accepts for the fields of a commit status.
+type statusLimits struct {
+	context     int
+	description int
+	targetURL   int
+}
+
+// GitHub's limits are used for drivers that are not listed.
+var defaultLimits = statusLimits{context: 255, description: 140, targetURL: 255}
+
+var driverLimits = map[scm.Driver]statusLimits{
+	scm.DriverGithub:    defaultLimits,
+	scm.DriverGitlab:    {context: 255, description: 255, targetURL: 255},
+	scm.DriverBitbucket: {context: 40, description: 255, targetURL: 255},
+	scm.DriverStash:     {context: 255, description: 255, targetURL: 450},
+}
+
+func limitsForDriver(d scm.Driver) statusLimits {
+	if l, ok := driverLimits[d]; ok {
+		return l
+	}
+	return defaultLimits
+}
+
+// truncate cuts s down to at most n characters.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// truncateWithEllipsis cuts s down to at most n characters, replacing the end
+// of the string with "..." if it was cut.
+func truncateWithEllipsis(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string(r[:n])
+	}
+	return string(r[:n-3]) + "..."
+}
@@ -0,0 +1,172 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var trackedRuns = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "commit_status_tracker_store_entries",
+		Help: "Number of reported states held in the tracking store",
+	},
+	[]string{"store"},
+)
+
+var pendingRuns = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "commit_status_tracker_pending_runs",
+		Help: "Number of runs held in the tracking store whose last reported state is pending",
+	},
+	[]string{"store"},
+)
+
+var rejectedTransitions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "commit_status_tracker_rejected_transitions_total",
+		Help: "Number of run states that were not reported because the run had already completed",
+	},
+	[]string{"kind", "from", "to"},
+)
+
+var deniedRepos = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "commit_status_tracker_denied_repositories_total",
+		Help: "Number of runs that were not reported because their repository is not allowed for their namespace",
+	},
+	[]string{"namespace"},
+)
+
+var postedStatuses = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "commit_status_tracker_statuses_posted_total",
+		Help: "Number of statuses reported to the Git hosting service",
+	},
+	[]string{"provider", "state", "namespace"},
+)
+
+var failedStatuses = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "commit_status_tracker_status_failures_total",
+		Help: "Number of statuses that could not be reported to the Git hosting service",
+	},
+	[]string{"provider", "class"},
+)
+
+var skippedRuns = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "commit_status_tracker_skipped_runs_total",
+		Help: "Number of reconciled runs whose status was not reported",
+	},
+	[]string{"kind", "reason"},
+)
+
+var scmRequestDuration = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "commit_status_tracker_scm_request_duration_seconds",
+		Help:    "Latency of requests to the Git hosting service",
+		Buckets: prometheus.DefBuckets,
+	},
+	[]string{"host", "method", "code"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(trackedRuns, pendingRuns, rejectedTransitions, deniedRepos,
+		postedStatuses, failedStatuses, skippedRuns, scmRequestDuration)
+}
+
+// These are the classes of errors that are counted when a status can't be
+// reported.
+const (
+	ErrorClassUnauthorized = "unauthorized"
+	ErrorClassForbidden    = "forbidden"
+	ErrorClassNotFound     = "not_found"
+	ErrorClassRateLimited  = "rate_limited"
+	ErrorClassClient       = "client_error"
+	ErrorClassServer       = "server_error"
+	ErrorClassTimeout      = "timeout"
+	ErrorClassNetwork      = "network"
+	ErrorClassUnknown      = "unknown"
+)
+
+// ErrorClass classifies an error returned by the Git hosting service, from
+// the status code of the response if there was one, errors are matched
+// through any wrapping.
+func ErrorClass(res *scm.Response, err error) string {
+	switch {
+	case IsUnauthorized(res, err):
+		return ErrorClassUnauthorized
+	case errors.Is(err, scm.ErrNotFound):
+		return ErrorClassNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	}
+	if res != nil && res.Status != 0 {
+		switch {
+		case res.Status == http.StatusTooManyRequests:
+			return ErrorClassRateLimited
+		case res.Status == http.StatusForbidden && res.Rate.Limit > 0 && res.Rate.Remaining == 0:
+			return ErrorClassRateLimited
+		case res.Status == http.StatusForbidden:
+			return ErrorClassForbidden
+		case res.Status == http.StatusNotFound:
+			return ErrorClassNotFound
+		case res.Status >= 500:
+			return ErrorClassServer
+		case res.Status >= 400:
+			return ErrorClassClient
+		}
+	}
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		if nerr.Timeout() {
+			return ErrorClassTimeout
+		}
+		return ErrorClassNetwork
+	}
+	return ErrorClassUnknown
+}
+
+// RecordStatusPosted counts a status that was reported for a run in the
+// namespace.
+func RecordStatusPosted(d scm.Driver, s scm.State, namespace string) {
+	postedStatuses.WithLabelValues(d.String(), s.String(), namespace).Inc()
+}
+
+// RecordStatusFailure counts a status that could not be reported, by the
+// class of the error.
+func RecordStatusFailure(d scm.Driver, res *scm.Response, err error) {
+	failedStatuses.WithLabelValues(d.String(), ErrorClass(res, err)).Inc()
+}
+
+// RecordSkippedRun counts a run of a Kind that was reconciled without
+// reporting a status, for example because it was already reported.
+func RecordSkippedRun(kind, reason string) {
+	skippedRuns.WithLabelValues(kind, reason).Inc()
+}
+
+// instrumentedTransport records the latency of requests to the Git hosting
+// service.
+type instrumentedTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(res.StatusCode)
+	}
+	scmRequestDuration.WithLabelValues(req.URL.Host, req.Method, code).Observe(time.Since(start).Seconds())
+	return res, err
+}
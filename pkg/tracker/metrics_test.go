@@ -0,0 +1,128 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+func TestErrorClass(t *testing.T) {
+	classTests := []struct {
+		name string
+		res  *scm.Response
+		err  error
+		want string
+	}{
+		{"not authorized", nil, scm.ErrNotAuthorized, ErrorClassUnauthorized},
+		{"unauthorized response", &scm.Response{Status: http.StatusUnauthorized}, errors.New("Bad credentials"), ErrorClassUnauthorized},
+		{"not found", nil, scm.ErrNotFound, ErrorClassNotFound},
+		{"not found response", &scm.Response{Status: http.StatusNotFound}, errors.New("Not Found"), ErrorClassNotFound},
+		{"forbidden", &scm.Response{Status: http.StatusForbidden, Rate: scm.Rate{Limit: 5000, Remaining: 10}}, errors.New("Forbidden"), ErrorClassForbidden},
+		{"rate limit exhausted", &scm.Response{Status: http.StatusForbidden, Rate: scm.Rate{Limit: 5000}}, errors.New("API rate limit exceeded"), ErrorClassRateLimited},
+		{"too many requests", &scm.Response{Status: http.StatusTooManyRequests}, errors.New("slow down"), ErrorClassRateLimited},
+		{"invalid status", &scm.Response{Status: http.StatusUnprocessableEntity}, errors.New("Validation Failed"), ErrorClassClient},
+		{"server error", &scm.Response{Status: http.StatusBadGateway}, errors.New("Bad Gateway"), ErrorClassServer},
+		{"deadline exceeded", nil, context.DeadlineExceeded, ErrorClassTimeout},
+		{"network error", nil, &url.Error{Op: "Post", URL: "https://api.github.com", Err: errors.New("connection refused")}, ErrorClassNetwork},
+		{"unknown error", nil, errors.New("unexpected"), ErrorClassUnknown},
+		{"wrapped not found", nil, fmt.Errorf("failed to resolve ref 'master' in tektoncd/triggers: %w", scm.ErrNotFound), ErrorClassNotFound},
+		{"wrapped not authorized", nil, fmt.Errorf("failed to resolve ref 'master' in tektoncd/triggers: %w", scm.ErrNotAuthorized), ErrorClassUnauthorized},
+		{"wrapped deadline exceeded", nil, fmt.Errorf("failed to resolve ref 'master' in tektoncd/triggers: %w", context.DeadlineExceeded), ErrorClassTimeout},
+		{"wrapped network error", nil, fmt.Errorf("failed to resolve ref 'master' in tektoncd/triggers: %w",
+			&url.Error{Op: "Get", URL: "https://api.github.com", Err: errors.New("connection refused")}), ErrorClassNetwork},
+	}
+
+	for _, tt := range classTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c := ErrorClass(tt.res, tt.err); c != tt.want {
+				t.Errorf("ErrorClass() got %#v, want %#v", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordStatusPosted(t *testing.T) {
+	counter := postedStatuses.WithLabelValues("github", "success", "test-namespace")
+	before := testutil.ToFloat64(counter)
+
+	RecordStatusPosted(scm.DriverGithub, scm.StateSuccess, "test-namespace")
+
+	if v := testutil.ToFloat64(counter); v != before+1 {
+		t.Fatalf("posted statuses got %v, want %v", v, before+1)
+	}
+}
+
+func TestRecordStatusFailure(t *testing.T) {
+	counter := failedStatuses.WithLabelValues("github", ErrorClassServer)
+	before := testutil.ToFloat64(counter)
+
+	RecordStatusFailure(scm.DriverGithub, &scm.Response{Status: http.StatusInternalServerError}, errors.New("Server Error"))
+
+	if v := testutil.ToFloat64(counter); v != before+1 {
+		t.Fatalf("status failures got %v, want %v", v, before+1)
+	}
+}
+
+func TestRecordSkippedRun(t *testing.T) {
+	counter := skippedRuns.WithLabelValues("TestRun", "AlreadyReported")
+	before := testutil.ToFloat64(counter)
+
+	RecordSkippedRun("TestRun", "AlreadyReported")
+
+	if v := testutil.ToFloat64(counter); v != before+1 {
+		t.Fatalf("skipped runs got %v, want %v", v, before+1)
+	}
+}
+
+func TestInstrumentedTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+	host := ts.Listener.Addr().String()
+	before := requestCount(t, host, "POST", "201")
+
+	cl := &http.Client{Transport: instrumentedTransport{base: http.DefaultTransport}}
+	res, err := cl.Post(ts.URL+"/repos/tektoncd/triggers/statuses/master", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if c := requestCount(t, host, "POST", "201"); c != before+1 {
+		t.Fatalf("request latency got %d observations, want %d", c, before+1)
+	}
+}
+
+// requestCount returns the number of requests recorded in the SCM request
+// latency histogram for the labels.
+func requestCount(t *testing.T, host, method, code string) uint64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != "commit_status_tracker_scm_request_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["host"] == host && labels["method"] == method && labels["code"] == code {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package tracker
+
+import (
+	"fmt"
+	"time"
+)
+
+// Options configures the behaviour of the tracking controllers.
+type Options struct {
+	// DashboardURL is the base URL of a Tekton Dashboard, if set, statuses
+	// without a target URL annotation link to the run in the Dashboard.
+	DashboardURL string
+
+	// MaxConcurrentReconciles is the number of runs of each kind that can be
+	// reconciled concurrently.
+	MaxConcurrentReconciles int
+
+	// StoreSize is the maximum number of reported states that are held in
+	// memory for each kind of run, it must be greater than zero.
+	StoreSize int
+
+	// StoreTTL is how long the reported states for completed runs are held in
+	// memory.
+	StoreTTL time.Duration
+
+	// ResyncInterval is how often the statuses of recently completed runs are
+	// compared with the statuses on the Git hosting service, if it's zero,
+	// statuses are not resynced.
+	ResyncInterval time.Duration
+
+	// ResyncWindow is how recently runs must have completed to be resynced.
+	ResyncWindow time.Duration
+
+	// ResyncRate is the number of requests per second that the resync can
+	// make to each Git hosting service, with bursts of up to ResyncBurst
+	// requests.
+	ResyncRate  float64
+	ResyncBurst int
+
+	// FinalizerTimeout is how long to keep retrying to report the status of a
+	// deleted run, before removing the finalizer anyway.
+	FinalizerTimeout time.Duration
+
+	// StartupWindow is how recently runs must have been updated to be
+	// reconciled when the operator starts.
+	StartupWindow time.Duration
+
+	// StartupRate is the number of runs per second that are reconciled when
+	// the operator starts, with bursts of up to StartupBurst runs.
+	StartupRate  float64
+	StartupBurst int
+
+	// CancelSuperseded cancels pending runs for older commits on a branch,
+	// when a run for a newer commit on the same branch reports the same
+	// context.
+	CancelSuperseded bool
+
+	// CredentialsNamespace is the namespace that holds central credentials,
+	// for runs in namespaces without a Secret of their own, if it's empty,
+	// central credentials are not used.
+	CredentialsNamespace string
+
+	// TokenFiles are the paths of files that hold the tokens for each host,
+	// if set, tokens are read from them instead of from Secrets.
+	TokenFiles map[string]string
+
+	// TokenHelper is a command that is run to get tokens, if set, it's used
+	// instead of TokenFiles or Secrets.
+	TokenHelper string
+
+	// RepoAllowlist restricts the repositories that runs in each namespace
+	// can report statuses for, if it's nil, all repositories are allowed.
+	RepoAllowlist RepoAllowlist
+
+	// CommitLocks serialises reporting the status for a context on a commit
+	// across the controllers for each kind of run, if it's nil, each
+	// controller only serialises its own runs.
+	CommitLocks *KeyedMutex
+}
+
+// Validate returns an error if the options can't be used.
+//
+// Rates and bursts must be positive, a zero burst would never allow a
+// request, and durations can't be negative.
+func (o Options) Validate() error {
+	if o.StoreSize <= 0 {
+		return fmt.Errorf("the maximum number of tracked runs must be greater than 0, got %d", o.StoreSize)
+	}
+	if o.ResyncRate <= 0 {
+		return fmt.Errorf("the resync rate must be greater than 0, got %v", o.ResyncRate)
+	}
+	if o.ResyncBurst <= 0 {
+		return fmt.Errorf("the resync burst must be greater than 0, got %d", o.ResyncBurst)
+	}
+	if o.StartupRate <= 0 {
+		return fmt.Errorf("the startup rate must be greater than 0, got %v", o.StartupRate)
+	}
+	if o.StartupBurst <= 0 {
+		return fmt.Errorf("the startup burst must be greater than 0, got %d", o.StartupBurst)
+	}
+	durations := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"tracked runs TTL", o.StoreTTL},
+		{"resync interval", o.ResyncInterval},
+		{"resync window", o.ResyncWindow},
+		{"finalizer timeout", o.FinalizerTimeout},
+		{"startup window", o.StartupWindow},
+	}
+	for _, d := range durations {
+		if d.d < 0 {
+			return fmt.Errorf("the %s can't be negative, got %s", d.name, d.d)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	validateTests := []struct {
+		name    string
+		change  func(o *Options)
+		wantErr string
+	}{
+		{"valid options", func(o *Options) {}, ""},
+		{"zero store size", func(o *Options) { o.StoreSize = 0 }, "the maximum number of tracked runs must be greater than 0, got 0"},
+		{"negative store size", func(o *Options) { o.StoreSize = -1 }, "the maximum number of tracked runs must be greater than 0, got -1"},
+		{"zero resync rate", func(o *Options) { o.ResyncRate = 0 }, "the resync rate must be greater than 0, got 0"},
+		{"zero resync burst", func(o *Options) { o.ResyncBurst = 0 }, "the resync burst must be greater than 0, got 0"},
+		{"negative startup rate", func(o *Options) { o.StartupRate = -1 }, "the startup rate must be greater than 0, got -1"},
+		{"zero startup burst", func(o *Options) { o.StartupBurst = 0 }, "the startup burst must be greater than 0, got 0"},
+		{"negative tracked runs TTL", func(o *Options) { o.StoreTTL = -time.Hour }, "the tracked runs TTL can't be negative, got -1h0m0s"},
+		{"negative resync interval", func(o *Options) { o.ResyncInterval = -time.Minute }, "the resync interval can't be negative, got -1m0s"},
+		{"negative resync window", func(o *Options) { o.ResyncWindow = -time.Hour }, "the resync window can't be negative, got -1h0m0s"},
+		{"negative finalizer timeout", func(o *Options) { o.FinalizerTimeout = -time.Minute }, "the finalizer timeout can't be negative, got -1m0s"},
+		{"negative startup window", func(o *Options) { o.StartupWindow = -time.Hour }, "the startup window can't be negative, got -1h0m0s"},
+	}
+
+	for _, tt := range validateTests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{StoreSize: 5000, StoreTTL: time.Hour, ResyncWindow: time.Hour, ResyncRate: 1, ResyncBurst: 5,
+				FinalizerTimeout: time.Minute * 5, StartupWindow: time.Hour, StartupRate: 5, StartupBurst: 10}
+			tt.change(&opts)
+
+			err := opts.Validate()
+			if !test.MatchError(t, tt.wantErr, err) {
+				t.Fatalf("Validate() got error %v, want %#v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package tracker
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportedStatus is the record of the last status that was reported for a
+// run, this is stored in annotations on the run, so that it survives restarts
+// of the operator.
+type ReportedStatus struct {
+	State   scm.State
+	Context string
+	Commit  string
+	// Retries is the number of times the run had been retried when the
+	// status was reported.
+	Retries int
+}
+
+// NewReportedStatus creates a record of reporting the status for a commit.
+func NewReportedStatus(s *scm.StatusInput, c *Commit) ReportedStatus {
+	return ReportedStatus{State: s.State, Context: s.Label, Commit: c.Ref}
+}
+
+// LastReported returns the status recorded in the annotations, or nil if no
+// status has been reported.
+func LastReported(ag annotationsGetter) *ReportedStatus {
+	a := ag.Annotations()
+	state, ok := a[ReportedStateName]
+	if !ok {
+		return nil
+	}
+	retries, _ := strconv.Atoi(a[ReportedRetriesName])
+	return &ReportedStatus{
+		State:   scm.ToState(state),
+		Context: a[ReportedContextName],
+		Commit:  a[ReportedCommitName],
+		Retries: retries,
+	}
+}
+
+// ReportedSHA returns the SHA that the last status was posted for, recorded
+// in the annotations, or "" if it wasn't recorded.
+func ReportedSHA(ag annotationsGetter) string {
+	return ag.Annotations()[ReportedSHAName]
+}
+
+// Matches returns true if the status has already been reported.
+func (r *ReportedStatus) Matches(s ReportedStatus) bool {
+	return r != nil && *r == s
+}
+
+// Annotate records the status in the annotations of the object, and the
+// state in the StateLabel.
+func (r ReportedStatus) Annotate(o metav1.Object) {
+	l := o.GetLabels()
+	if l == nil {
+		l = map[string]string{}
+	}
+	l[StateLabel] = r.State.String()
+	o.SetLabels(l)
+
+	a := o.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	a[ReportedStateName] = r.State.String()
+	a[ReportedContextName] = r.Context
+	a[ReportedCommitName] = r.Commit
+	if r.Retries > 0 {
+		a[ReportedRetriesName] = strconv.Itoa(r.Retries)
+	} else {
+		delete(a, ReportedRetriesName)
+	}
+	o.SetAnnotations(a)
+}
+
+// PostDetails are the details of the last post of a status to the Git hosting
+// service.
+type PostDetails struct {
+	// SHA is the commit that the status was posted for, the commit's Ref
+	// resolved when it's a branch or tag.
+	SHA string
+	// TargetURL is the URL that the status links to.
+	TargetURL string
+	// RequestID identifies the request to the Git hosting service, e.g.
+	// GitHub's X-GitHub-Request-Id, services don't return an ID for the
+	// status itself.
+	RequestID string
+	Time      time.Time
+}
+
+// NewPostDetails creates the details of posting the status for the sha at
+// time t.
+func NewPostDetails(sha string, s *scm.Status, res *scm.Response, t time.Time) PostDetails {
+	p := PostDetails{SHA: sha, Time: t}
+	if s != nil {
+		p.TargetURL = s.Target
+	}
+	if res != nil {
+		p.RequestID = res.ID
+	}
+	return p
+}
+
+// Annotate records the details in the annotations of the object, details
+// that are empty are removed.
+func (p PostDetails) Annotate(o metav1.Object) {
+	a := o.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	setOrDelete(a, ReportedSHAName, p.SHA)
+	setOrDelete(a, ReportedTargetURLName, p.TargetURL)
+	setOrDelete(a, ReportedRequestIDName, p.RequestID)
+	a[ReportedAtName] = p.Time.UTC().Format(time.RFC3339)
+	o.SetAnnotations(a)
+}
+
+func setOrDelete(m map[string]string, k, v string) {
+	if v == "" {
+		delete(m, k)
+		return
+	}
+	m[k] = v
+}
@@ -0,0 +1,123 @@
+package tracker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	tb "github.com/tektoncd/pipeline/test/builder"
+)
+
+func TestLastReported(t *testing.T) {
+	reportedTests := []struct {
+		name        string
+		annotations map[string]string
+		want        *ReportedStatus
+	}{
+		{"no annotations", map[string]string{}, nil},
+		{"reported annotations", map[string]string{
+			ReportedStateName:   "failure",
+			ReportedContextName: "test-context",
+			ReportedCommitName:  "e1466db56110fa1b813277c1647e20283d3370c3",
+		}, &ReportedStatus{State: scm.StateFailure, Context: "test-context", Commit: "e1466db56110fa1b813277c1647e20283d3370c3"}},
+		{"retried run", map[string]string{
+			ReportedStateName:   "pending",
+			ReportedContextName: "test-context",
+			ReportedCommitName:  "master",
+			ReportedRetriesName: "2",
+		}, &ReportedStatus{State: scm.StatePending, Context: "test-context", Commit: "master", Retries: 2}},
+	}
+
+	for _, tt := range reportedTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := LastReported(fakeObject{annotations: tt.annotations})
+			if !reflect.DeepEqual(r, tt.want) {
+				t.Errorf("LastReported() got %#v, want %#v", r, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportedStatusMatches(t *testing.T) {
+	reported := ReportedStatus{State: scm.StatePending, Context: "test-context", Commit: "master"}
+	matchTests := []struct {
+		name string
+		last *ReportedStatus
+		want bool
+	}{
+		{"nothing reported", nil, false},
+		{"same status", &ReportedStatus{State: scm.StatePending, Context: "test-context", Commit: "master"}, true},
+		{"different state", &ReportedStatus{State: scm.StateSuccess, Context: "test-context", Commit: "master"}, false},
+		{"different context", &ReportedStatus{State: scm.StatePending, Context: "other-context", Commit: "master"}, false},
+		{"different commit", &ReportedStatus{State: scm.StatePending, Context: "test-context", Commit: "develop"}, false},
+	}
+
+	for _, tt := range matchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b := tt.last.Matches(reported); b != tt.want {
+				t.Errorf("Matches() got %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportedStatusAnnotate(t *testing.T) {
+	pr := tb.PipelineRun("test-pipeline-run", "foo", tb.PipelineRunAnnotation(NotifiableName, "true"))
+	reported := NewReportedStatus(
+		&scm.StatusInput{State: scm.StateSuccess, Label: "test-context"},
+		&Commit{RepoURL: "https://github.com/tektoncd/triggers", Ref: "master"})
+
+	reported.Annotate(pr)
+
+	want := map[string]string{
+		NotifiableName:      "true",
+		ReportedStateName:   "success",
+		ReportedContextName: "test-context",
+		ReportedCommitName:  "master",
+	}
+	if !reflect.DeepEqual(pr.Annotations, want) {
+		t.Fatalf("Annotate() got %#v, want %#v", pr.Annotations, want)
+	}
+	if r := LastReported(wrapper{pr}); !reflect.DeepEqual(*r, reported) {
+		t.Fatalf("LastReported() got %#v, want %#v", r, reported)
+	}
+	if l := pr.Labels[StateLabel]; l != "success" {
+		t.Fatalf("Annotate() got state label %#v, want %#v", l, "success")
+	}
+}
+
+func TestPostDetailsAnnotate(t *testing.T) {
+	postTests := []struct {
+		name   string
+		status *scm.Status
+		res    *scm.Response
+		want   map[string]string
+	}{
+		{"all details", &scm.Status{State: scm.StateSuccess, Target: "https://example.com/logs"}, &scm.Response{ID: "0A1B:2C3D"},
+			map[string]string{
+				ReportedSHAName:       "e1466db56110fa1b813277c1647e20283d3370c3",
+				ReportedTargetURLName: "https://example.com/logs",
+				ReportedRequestIDName: "0A1B:2C3D",
+				ReportedAtName:        "2020-01-10T12:00:00Z",
+			}},
+		{"no response", &scm.Status{State: scm.StateSuccess}, nil,
+			map[string]string{
+				ReportedSHAName: "e1466db56110fa1b813277c1647e20283d3370c3",
+				ReportedAtName:  "2020-01-10T12:00:00Z",
+			}},
+	}
+
+	for _, tt := range postTests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr := tb.PipelineRun("test-pipeline-run", "foo", tb.PipelineRunAnnotation(ReportedTargetURLName, "https://example.com/old"))
+			posted := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+
+			NewPostDetails("e1466db56110fa1b813277c1647e20283d3370c3", tt.status, tt.res, posted).Annotate(pr)
+
+			if !reflect.DeepEqual(pr.Annotations, tt.want) {
+				t.Fatalf("Annotate() got %#v, want %#v", pr.Annotations, tt.want)
+			}
+		})
+	}
+}
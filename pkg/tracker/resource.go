@@ -26,6 +26,15 @@ func (c Commit) Repo() (string, error) {
 	return extractRepoFromGitHubURL(c.RepoURL)
 }
 
+// Host extracts the host from the Commit's RepoURL.
+func (c Commit) Host() (string, error) {
+	u, err := url.Parse(c.RepoURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse repo URL %s: %w", c.RepoURL, err)
+	}
+	return u.Host, nil
+}
+
 // FindCommit extracts the details of commit/ref from a "git" PipelineResource.
 //
 // An error is returned if:
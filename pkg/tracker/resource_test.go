@@ -67,6 +67,30 @@ func TestCommit(t *testing.T) {
 	}
 }
 
+func TestCommitHost(t *testing.T) {
+	hostTests := []struct {
+		repoURL string
+		want    string
+		wantErr string
+	}{
+		{"https://github.com/test/test.git", "github.com", ""},
+		{"https://gitlab.example.com:8443/org/repo", "gitlab.example.com:8443", ""},
+		{"http://192.168.0.%31/test/repo", "", "failed to parse repo URL"},
+	}
+
+	for _, tt := range hostTests {
+		c := Commit{RepoURL: tt.repoURL}
+		h, err := c.Host()
+		if !test.MatchError(t, tt.wantErr, err) {
+			t.Errorf("Host() %s: got error %v, want %s", tt.repoURL, err, tt.wantErr)
+			continue
+		}
+		if h != tt.want {
+			t.Errorf("Host() %s: got %#v, want %#v", tt.repoURL, h, tt.want)
+		}
+	}
+}
+
 func TestExtractRepoFromGitHubURL(t *testing.T) {
 	repoURLTests := []struct {
 		name    string
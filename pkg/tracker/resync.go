@@ -0,0 +1,212 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var resyncLog = logf.Log.WithName("resync")
+
+// statusPageSize is the number of statuses that are requested in each page.
+const statusPageSize = 100
+
+// RunLister returns the runs that completed after the provided time.
+type RunLister func(ctx context.Context, since time.Time) ([]Trackable, error)
+
+// SupersededFunc returns true if a newer run reports the same context for
+// the commit in the repository.
+type SupersededFunc func(ctx context.Context, r Trackable, repo, ref, context string, d scm.Driver) (bool, error)
+
+// Resyncer periodically compares the statuses for recently completed runs
+// with the statuses for the commit on the Git hosting service, and reports
+// the status again if they differ, for example if the status was overwritten,
+// or the request to report it was lost.
+//
+// Statuses are checked for the SHA that the last status was posted for, so
+// that if the run's revision is a branch that has moved since, the status
+// isn't reported for a commit that the run didn't build.
+//
+// Statuses are only reported again if they could be reported by reconciling
+// the run, statuses of superseded runs, and of runs that completed after
+// another state was reported, e.g. runs that were cancelled, are left alone.
+//
+// Requests to the Git hosting service are rate-limited per host.
+type Resyncer struct {
+	tokens     TokenProvider
+	scmFactory SCMClientFactory
+	list       RunLister
+	superseded SupersededFunc
+	options    Options
+	clock      func() time.Time
+
+	limitersMu sync.Mutex
+	limiters   map[string]*rate.Limiter
+}
+
+// NewResyncer creates a Resyncer that checks the runs returned by the lister,
+// runs are not resynced if s returns true.
+func NewResyncer(t TokenProvider, f SCMClientFactory, l RunLister, s SupersededFunc, opts Options) *Resyncer {
+	return &Resyncer{
+		tokens:     t,
+		scmFactory: f,
+		list:       l,
+		superseded: s,
+		options:    opts,
+		clock:      time.Now,
+		limiters:   make(map[string]*rate.Limiter),
+	}
+}
+
+// Start implements manager.Runnable, checking for drift every
+// ResyncInterval until the stop channel is closed.
+func (r *Resyncer) Start(stop <-chan struct{}) error {
+	wait.Until(func() {
+		if err := r.Resync(context.Background()); err != nil {
+			resyncLog.Error(err, "failed to resync statuses")
+		}
+	}, r.options.ResyncInterval, stop)
+	return nil
+}
+
+// Resync checks the runs that completed within the ResyncWindow, reporting
+// the status again for any that differ from the latest status for the
+// context on the Git hosting service.
+//
+// Runs on hosts that have exceeded their rate-limit are skipped until the
+// next Resync.
+func (r *Resyncer) Resync(ctx context.Context) error {
+	runs, err := r.list(ctx, r.clock().Add(-r.options.ResyncWindow))
+	if err != nil {
+		return err
+	}
+	for _, run := range runs {
+		if err := r.resyncRun(ctx, run); err != nil {
+			resyncLog.Error(err, "failed to resync run", "namespace", run.GetNamespace(), "name", run.GetName())
+		}
+	}
+	return nil
+}
+
+func (r *Resyncer) resyncRun(ctx context.Context, run Trackable) error {
+	reqLogger := resyncLog.WithValues("namespace", run.GetNamespace(), "name", run.GetName())
+	last := LastReported(run)
+	if !IsNotifiable(run) || last == nil {
+		return nil
+	}
+	if !ValidTransition(last.RunState(), run.RunState()) {
+		reqLogger.Info("not resyncing a state transition for a completed run", "from", last.RunState(), "to", run.RunState())
+		return nil
+	}
+	commit, err := run.FindCommit()
+	if err != nil {
+		return err
+	}
+	repo, err := commit.Repo()
+	if err != nil {
+		return err
+	}
+	if err := r.options.RepoAllowlist.CheckRepo(run.GetNamespace(), repo); err != nil {
+		reqLogger.Info("not resyncing the status for a repository that is not allowed", "repo", repo)
+		return nil
+	}
+	host, err := commit.Host()
+	if err != nil {
+		return err
+	}
+	if !r.limiterFor(host).Allow() {
+		reqLogger.Info("rate limit exceeded for host, skipping run", "host", host)
+		return nil
+	}
+
+	secret, err := r.tokens.Token(ctx, run, commit)
+	if err != nil {
+		return err
+	}
+	scmClient := r.scmFactory(secret)
+	sha := ReportedSHA(run)
+	if sha == "" {
+		sha, _, err = ResolveSHA(ctx, scmClient, repo, commit)
+		if err != nil {
+			return err
+		}
+	}
+	latest, err := findStatus(ctx, scmClient, repo, sha, last.Context)
+	if err != nil {
+		return err
+	}
+	want := convertState(run.RunState())
+	if latest != nil && latest.State == want {
+		return nil
+	}
+	if r.superseded != nil {
+		superseded, err := r.superseded(ctx, run, repo, commit.Ref, last.Context, scmClient.Driver)
+		if err != nil {
+			return err
+		}
+		if superseded {
+			reqLogger.Info("not resyncing the status of a superseded run", "repo", repo, "sha", sha)
+			return nil
+		}
+	}
+
+	input, err := GetCommitStatusInput(run, scmClient.Driver, r.options.DashboardURL)
+	if err != nil {
+		return err
+	}
+	reqLogger.Info("status has drifted, reporting it again", "repo", repo, "sha", sha, "status", input)
+	_, res, err := scmClient.Repositories.CreateStatus(ctx, repo, sha, input)
+	if err != nil {
+		RecordStatusFailure(scmClient.Driver, res, err)
+		return err
+	}
+	RecordStatusPosted(scmClient.Driver, input.State, run.GetNamespace())
+	return nil
+}
+
+func (r *Resyncer) limiterFor(host string) *rate.Limiter {
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+	l, ok := r.limiters[host]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(r.options.ResyncRate), r.options.ResyncBurst)
+		r.limiters[host] = l
+	}
+	return l
+}
+
+// findStatus returns the most recent status for the context on the commit,
+// paging through the statuses until it's found, or nil if there is none.
+func findStatus(ctx context.Context, scmClient *scm.Client, repo, sha, context string) (*scm.Status, error) {
+	opts := scm.ListOptions{Page: 1, Size: statusPageSize}
+	for {
+		statuses, res, err := scmClient.Repositories.ListStatus(ctx, repo, sha, opts)
+		if err != nil {
+			return nil, err
+		}
+		if s := latestStatus(statuses, context); s != nil {
+			return s, nil
+		}
+		if res == nil || res.Page.Next <= opts.Page {
+			return nil, nil
+		}
+		opts.Page = res.Page.Next
+	}
+}
+
+// latestStatus returns the most recent status for the context.
+//
+// Git hosting services list statuses with the most recent first.
+func latestStatus(statuses []*scm.Status, context string) *scm.Status {
+	for _, s := range statuses {
+		if s.Label == context {
+			return s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	tb "github.com/bigkevmcd/commit-status-tracker/test/builder"
+)
+
+func TestResync(t *testing.T) {
+	resyncTests := []struct {
+		name       string
+		reported   scm.State
+		state      State
+		superseded bool
+		existing   []*scm.Status
+		want       []*scm.Status
+	}{
+		{"status matches", scm.StateSuccess, Successful, false,
+			[]*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "existing"}},
+			[]*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "existing"}}},
+		{"status overwritten", scm.StateSuccess, Successful, false,
+			[]*scm.Status{{State: scm.StatePending, Label: "test-context", Desc: "existing"}},
+			[]*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "testing"}}},
+		{"status missing", scm.StateSuccess, Successful, false,
+			[]*scm.Status{{State: scm.StateFailure, Label: "other-context", Desc: "existing"}},
+			[]*scm.Status{
+				{State: scm.StateFailure, Label: "other-context", Desc: "existing"},
+				{State: scm.StateSuccess, Label: "test-context", Desc: "testing"}}},
+		{"completed since pending was reported", scm.StatePending, Failed, false,
+			[]*scm.Status{{State: scm.StatePending, Label: "test-context", Desc: "existing"}},
+			[]*scm.Status{{State: scm.StateFailure, Label: "test-context", Desc: "testing"}}},
+		{"superseded run", scm.StatePending, Failed, true,
+			[]*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "newer"}},
+			[]*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "newer"}}},
+		{"cancelled run", scm.StateError, Failed, false,
+			[]*scm.Status{{State: scm.StateError, Label: "test-context", Desc: "superseded by newer-run"}},
+			[]*scm.Status{{State: scm.StateError, Label: "test-context", Desc: "superseded by newer-run"}}},
+	}
+
+	for _, tt := range resyncTests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := makeResyncRun("test-run", "master", tt.reported)
+			run.details.State = tt.state
+			r, data := makeResyncer(Options{ResyncWindow: time.Hour, ResyncRate: 1, ResyncBurst: 5}, run)
+			r.superseded = func(ctx context.Context, run Trackable, repo, ref, context string, d scm.Driver) (bool, error) {
+				return tt.superseded, nil
+			}
+			data.Statuses["master"] = tt.existing
+
+			if err := r.Resync(context.TODO()); err != nil {
+				t.Fatal(err)
+			}
+			assertStatuses(t, data.Statuses["master"], tt.want)
+		})
+	}
+}
+
+func TestResyncSkipsUnreportedRuns(t *testing.T) {
+	run := makeResyncRun("test-run", "master", scm.StateSuccess)
+	run.annotations = map[string]string{NotifiableName: "true", StatusContextName: "test-context"}
+	r, data := makeResyncer(Options{ResyncWindow: time.Hour, ResyncRate: 1, ResyncBurst: 5}, run)
+
+	if err := r.Resync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	assertStatuses(t, data.Statuses["master"], nil)
+}
+
+// TestResyncWithMovedBranch tests that the status is checked, and reported
+// again, for the SHA that it was reported for, and not for the commit that the
+// run's branch points to now.
+func TestResyncWithMovedBranch(t *testing.T) {
+	run := makeResyncRun("test-run", "master", scm.StateSuccess)
+	run.annotations[ReportedSHAName] = "e1466db56110fa1b813277c1647e20283d3370c3"
+	r, data := makeResyncer(Options{ResyncWindow: time.Hour, ResyncRate: 1, ResyncBurst: 5}, run)
+	data.Commits["master"] = &scm.Commit{Sha: "a6b3dd2e3d1a11eab77f2e728ce881255aca1e26"}
+	data.Statuses["e1466db56110fa1b813277c1647e20283d3370c3"] = []*scm.Status{
+		{State: scm.StatePending, Label: "test-context", Desc: "existing"}}
+
+	if err := r.Resync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	assertStatuses(t, data.Statuses["e1466db56110fa1b813277c1647e20283d3370c3"],
+		[]*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "testing"}})
+	assertStatuses(t, data.Statuses["a6b3dd2e3d1a11eab77f2e728ce881255aca1e26"], nil)
+}
+
+// TestResyncWithoutReportedSHA tests that the run's revision is resolved if
+// the SHA wasn't recorded when the status was reported.
+func TestResyncWithoutReportedSHA(t *testing.T) {
+	run := makeResyncRun("test-run", "master", scm.StateSuccess)
+	delete(run.annotations, ReportedSHAName)
+	r, data := makeResyncer(Options{ResyncWindow: time.Hour, ResyncRate: 1, ResyncBurst: 5}, run)
+	data.Commits["master"] = &scm.Commit{Sha: "e1466db56110fa1b813277c1647e20283d3370c3"}
+
+	if err := r.Resync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	assertStatuses(t, data.Statuses["e1466db56110fa1b813277c1647e20283d3370c3"],
+		[]*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "testing"}})
+}
+
+func TestResyncPagesThroughStatuses(t *testing.T) {
+	run := makeResyncRun("test-run", "master", scm.StateSuccess)
+	r, data := makeResyncer(Options{ResyncWindow: time.Hour, ResyncRate: 1, ResyncBurst: 5}, run)
+	scmClient := r.scmFactory(testToken)
+	r.scmFactory = func(s string) *scm.Client {
+		return &scm.Client{Driver: scmClient.Driver, Git: scmClient.Git, Repositories: pagedStatuses{scmClient.Repositories}}
+	}
+	existing := []*scm.Status{
+		{State: scm.StateFailure, Label: "other-context", Desc: "existing"},
+		{State: scm.StateSuccess, Label: "another-context", Desc: "existing"},
+		{State: scm.StateSuccess, Label: "test-context", Desc: "existing"},
+	}
+	data.Statuses["master"] = existing
+
+	if err := r.Resync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	assertStatuses(t, data.Statuses["master"], existing)
+}
+
+// pagedStatuses lists statuses one at a time, with the page of the next
+// status in the response.
+type pagedStatuses struct {
+	scm.RepositoryService
+}
+
+func (p pagedStatuses) ListStatus(ctx context.Context, repo, ref string, opts scm.ListOptions) ([]*scm.Status, *scm.Response, error) {
+	statuses, _, err := p.RepositoryService.ListStatus(ctx, repo, ref, opts)
+	if err != nil || opts.Page > len(statuses) {
+		return nil, &scm.Response{}, err
+	}
+	res := &scm.Response{}
+	if opts.Page < len(statuses) {
+		res.Page.Next = opts.Page + 1
+	}
+	return statuses[opts.Page-1 : opts.Page], res, nil
+}
+
+func TestResyncIsRateLimitedPerHost(t *testing.T) {
+	r, data := makeResyncer(Options{ResyncWindow: time.Hour, ResyncRate: 0.001, ResyncBurst: 1},
+		makeResyncRun("first-run", "first", scm.StateSuccess),
+		makeResyncRun("second-run", "second", scm.StateSuccess))
+
+	if err := r.Resync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	assertStatuses(t, data.Statuses["first"], []*scm.Status{{State: scm.StateSuccess, Label: "test-context", Desc: "testing"}})
+	assertStatuses(t, data.Statuses["second"], nil)
+}
+
+func TestResyncListsRunsWithinWindow(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	var since time.Time
+	r := NewResyncer(nil, nil, func(ctx context.Context, t time.Time) ([]Trackable, error) {
+		since = t
+		return nil, nil
+	}, nil, Options{ResyncWindow: time.Minute * 30})
+	r.clock = func() time.Time { return now }
+
+	if err := r.Resync(context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+	if want := now.Add(-time.Minute * 30); !since.Equal(want) {
+		t.Fatalf("runs listed since %v, want %v", since, want)
+	}
+}
+
+func makeResyncer(opts Options, runs ...Trackable) (*Resyncer, *fakescm.Data) {
+	objs := []runtime.Object{
+		tb.MakeSecret(SecretName, map[string][]byte{"token": []byte(testToken)}),
+	}
+	cl := fake.NewFakeClient(objs...)
+	scmClient, data := fakescm.NewDefault()
+	factory := func(s string) *scm.Client {
+		return scmClient
+	}
+	lister := func(ctx context.Context, since time.Time) ([]Trackable, error) {
+		return runs, nil
+	}
+	return NewResyncer(NewSecretTokenProvider(cl, cl, ""), factory, lister, nil, opts), data
+}
+
+func makeResyncRun(name, ref string, reported scm.State) fakeObject {
+	return fakeObject{
+		annotations: map[string]string{
+			NotifiableName:        "true",
+			StatusContextName:     "test-context",
+			StatusDescriptionName: "testing",
+			ReportedStateName:     reported.String(),
+			ReportedContextName:   "test-context",
+			ReportedCommitName:    ref,
+			ReportedSHAName:       ref,
+		},
+		details: RunDetails{Name: name, Namespace: "test-namespace", State: Successful},
+		commit:  &Commit{RepoURL: "https://github.com/tektoncd/triggers", Ref: ref},
+	}
+}
+
+func assertStatuses(t *testing.T, got, want []*scm.Status) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d statuses, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if *got[i] != *want[i] {
+			t.Errorf("status %d got %#v, want %#v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package tracker
+
+import (
+	"strings"
+	"time"
+
+	pipelinev1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RunDetails is the information about a PipelineRun or TaskRun that is
+// available to templated annotations.
+type RunDetails struct {
+	// Kind is either "PipelineRun" or "TaskRun".
+	Kind      string
+	Name      string
+	Namespace string
+	// PipelineName is the name of the Pipeline that was run, for TaskRuns this
+	// is taken from the "tekton.dev/pipeline" label, and is empty if the
+	// TaskRun was not part of a PipelineRun.
+	PipelineName string
+	Labels       map[string]string
+	Params       map[string]string
+	// Duration is zero until the run has completed.
+	Duration time.Duration
+	// FailedTasks is a sorted list of the names of the tasks that failed.
+	FailedTasks []string
+	// Failure describes why the run failed, it is empty if the run did not
+	// fail.
+	Failure string
+	State   State
+	// ServiceAccountName is the service account that the run uses, it is
+	// empty if the run uses the namespace's default service account.
+	ServiceAccountName string
+}
+
+// ParamsToMap converts Tekton params to a map, array values are joined with
+// a ",".
+func ParamsToMap(params []pipelinev1.Param) map[string]string {
+	m := make(map[string]string)
+	for _, p := range params {
+		if p.Value.Type == pipelinev1.ParamTypeArray {
+			m[p.Name] = strings.Join(p.Value.ArrayVal, ",")
+			continue
+		}
+		m[p.Name] = p.Value.StringVal
+	}
+	return m
+}
+
+// RunDuration returns the time between the start and completion of a run, or
+// zero if the run has not completed.
+func RunDuration(start, completion *metav1.Time) time.Duration {
+	if start == nil || completion == nil {
+		return 0
+	}
+	return completion.Sub(start.Time)
+}
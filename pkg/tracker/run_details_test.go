@@ -0,0 +1,44 @@
+package tracker
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tb "github.com/tektoncd/pipeline/test/builder"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParamsToMap(t *testing.T) {
+	spec := tb.PipelineRun("test-pipeline-run", "foo", tb.PipelineRunSpec("test-pipeline",
+		tb.PipelineRunParam("environment", "staging"),
+		tb.PipelineRunParam("flags", "-v", "-race"))).Spec
+
+	want := map[string]string{"environment": "staging", "flags": "-v,-race"}
+	if m := ParamsToMap(spec.Params); !reflect.DeepEqual(m, want) {
+		t.Fatalf("ParamsToMap() got %#v, want %#v", m, want)
+	}
+}
+
+func TestRunDuration(t *testing.T) {
+	start := metav1.NewTime(time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(time.Minute * 5))
+	durationTests := []struct {
+		name       string
+		start      *metav1.Time
+		completion *metav1.Time
+		want       time.Duration
+	}{
+		{"not started", nil, nil, 0},
+		{"not completed", &start, nil, 0},
+		{"completed", &start, &end, time.Minute * 5},
+	}
+
+	for _, tt := range durationTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := RunDuration(tt.start, tt.completion); d != tt.want {
+				t.Errorf("RunDuration() got %v, want %v", d, tt.want)
+			}
+		})
+	}
+}
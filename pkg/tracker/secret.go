@@ -3,9 +3,12 @@ package tracker
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -15,28 +18,121 @@ const (
 	secretID   = "token"
 )
 
+// secretRef identifies the key in a Secret that holds the token, and the
+// annotations that named them, if any.
+type secretRef struct {
+	name     string
+	key      string
+	nameFrom string
+	keyFrom  string
+	// notFound, if set, is used to find the token when the Secret doesn't
+	// exist, if it finds no token, the Secret's absence is an error.
+	notFound func() (string, error)
+}
+
 // GetAuthSecret attempts to find a Secret in the provided namespace, using the
 // client.
 //
 // Returns the string of the secret if found, otherwise returns an error.
-func GetAuthSecret(c client.Client, ns string) (string, error) {
+func GetAuthSecret(c client.Reader, ns string) (string, error) {
+	return secretRef{name: SecretName, key: secretID}.token(c, ns)
+}
+
+// GetAuthSecretForRun attempts to find the Secret for a run's commit, using
+// the client.
+//
+// The Secret and key can be chosen with the StatusSecretName and
+// StatusSecretKeyName annotations on the run, the Secret must be in the same
+// namespace as the run.
+//
+// Otherwise, the same Secret as GetAuthSecret is used, and if it doesn't
+// exist, the secrets linked to the run's service account are used if one is
+// annotated for the commit's host in the same way as for Tekton's git
+// credentials.
+//
+// If none of those exist, and central is not nil, a central credential is
+// used if its policy allows the run's namespace to use it for the owner of
+// the commit's repository.
+func GetAuthSecretForRun(c client.Reader, r Trackable, commit *Commit, central *CentralCredentials) (string, error) {
+	ref, err := secretRefFor(r)
+	if err != nil {
+		return "", err
+	}
+	if ref.nameFrom == "" && ref.keyFrom == "" {
+		ref.notFound = func() (string, error) {
+			host, err := commit.Host()
+			if err != nil {
+				return "", err
+			}
+			token, err := serviceAccountToken(c, r.GetNamespace(), r.RunDetails().ServiceAccountName, host)
+			if err != nil {
+				return "", fmt.Errorf("failed to GetAuthSecret, error getting git credentials for %s: %w", host, err)
+			}
+			if token != "" || central == nil {
+				return token, nil
+			}
+			repo, err := commit.Repo()
+			if err != nil {
+				return "", err
+			}
+			return centralToken(central.Reader, central.Namespace, r.GetNamespace(), strings.SplitN(repo, "/", 2)[0])
+		}
+	}
+	return ref.token(c, r.GetNamespace())
+}
+
+func secretRefFor(r Trackable) (secretRef, error) {
+	ref := secretRef{name: SecretName, key: secretID}
+	a := r.Annotations()
+	if v, ok := a[StatusSecretName]; ok {
+		name := v
+		if i := strings.Index(v, "/"); i >= 0 {
+			if ns := v[:i]; ns != r.GetNamespace() {
+				return ref, fmt.Errorf("failed to GetAuthSecret, secret '%s' from annotation %s is not in the run's namespace '%s'", v, StatusSecretName, r.GetNamespace())
+			}
+			name = v[i+1:]
+		}
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return ref, fmt.Errorf("failed to GetAuthSecret, invalid secret name '%s' from annotation %s: %s", v, StatusSecretName, strings.Join(errs, ", "))
+		}
+		ref.name, ref.nameFrom = name, StatusSecretName
+	}
+	if v, ok := a[StatusSecretKeyName]; ok {
+		if v == "" {
+			return ref, fmt.Errorf("failed to GetAuthSecret, empty key from annotation %s", StatusSecretKeyName)
+		}
+		ref.key, ref.keyFrom = v, StatusSecretKeyName
+	}
+	return ref, nil
+}
+
+func (s secretRef) token(c client.Reader, ns string) (string, error) {
 	secret := &corev1.Secret{}
-	err := c.Get(context.TODO(), getNamespaceSecretName(ns), secret)
+	err := c.Get(context.TODO(), types.NamespacedName{Namespace: ns, Name: s.name}, secret)
+	if errors.IsNotFound(err) && s.notFound != nil {
+		token, ferr := s.notFound()
+		if ferr != nil || token != "" {
+			return token, ferr
+		}
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to GetAuthSecret, error getting secret '%s' in namespace '%s': '%q'", SecretName, ns, err)
+		return "", fmt.Errorf("failed to GetAuthSecret, error getting secret '%s' in namespace '%s'%s: '%q'", s.name, ns, fromAnnotation(s.nameFrom), err)
 	}
 
-	tokenData, ok := secret.Data[secretID]
+	tokenData, ok := secret.Data[s.key]
 	if !ok {
-		return "", fmt.Errorf("failed to GetAuthSecret, secret %s does not have a 'token' key", ns)
+		keyFrom := s.keyFrom
+		if keyFrom == "" {
+			keyFrom = s.nameFrom
+		}
+		return "", fmt.Errorf("failed to GetAuthSecret, secret '%s' does not have a '%s' key%s", s.name, s.key, fromAnnotation(keyFrom))
 	}
 	return string(tokenData), nil
 }
 
-func getNamespaceSecretName(s string) types.NamespacedName {
-	return types.NamespacedName{
-		Namespace: s,
-		Name:      SecretName,
+func fromAnnotation(name string) string {
+	if name == "" {
+		return ""
 	}
-
+	return fmt.Sprintf(" (from annotation %s)", name)
 }
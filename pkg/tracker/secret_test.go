@@ -14,6 +14,8 @@ const (
 	testToken = "abcdefghijklmnopqrstuvwxyz12345678901234"
 )
 
+var testCommit = &Commit{RepoURL: "https://github.com/tektoncd/triggers", Ref: "master"}
+
 func TestGetAuthSecretWithExistingToken(t *testing.T) {
 	logf.SetLogger(logf.ZapLogger(true))
 	secret := tb.MakeSecret(SecretName, map[string][]byte{"token": []byte(testToken)})
@@ -59,3 +61,72 @@ func TestGetAuthSecretWithNoToken(t *testing.T) {
 		t.Fatalf("failed to match error when no secret: got %s, want %s", err, wantErr)
 	}
 }
+
+func TestGetAuthSecretForRun(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	cl := fake.NewFakeClient(
+		tb.MakeSecret(SecretName, map[string][]byte{"token": []byte(testToken)}),
+		tb.MakeSecret("org-secret", map[string][]byte{"token": []byte("org-token"), "other": []byte("other-token")}),
+	)
+
+	secretTests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+	}{
+		{"no annotations", map[string]string{}, testToken},
+		{"secret annotation", map[string]string{StatusSecretName: "org-secret"}, "org-token"},
+		{"secret annotation with namespace", map[string]string{StatusSecretName: "test-namespace/org-secret"}, "org-token"},
+		{"secret and key annotations", map[string]string{StatusSecretName: "org-secret", StatusSecretKeyName: "other"}, "other-token"},
+	}
+
+	for _, tt := range secretTests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := fakeObject{annotations: tt.annotations, details: RunDetails{Namespace: "test-namespace"}}
+			sec, err := GetAuthSecretForRun(cl, run, testCommit, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sec != tt.want {
+				t.Fatalf("got %s, want %s", sec, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetAuthSecretForRunErrors(t *testing.T) {
+	logf.SetLogger(logf.ZapLogger(true))
+	cl := fake.NewFakeClient(
+		tb.MakeSecret("org-secret", map[string][]byte{"token": []byte(testToken)}),
+		tb.MakeSecret(SecretName, map[string][]byte{"token": []byte(testToken)}),
+	)
+
+	errorTests := []struct {
+		name        string
+		annotations map[string]string
+		wantErr     string
+	}{
+		{"missing secret", map[string]string{StatusSecretName: "missing-secret"},
+			"error getting secret 'missing-secret' in namespace 'test-namespace' \\(from annotation tekton.dev/status-secret\\):.* not found"},
+		{"secret in another namespace", map[string]string{StatusSecretName: "other-namespace/org-secret"},
+			"secret 'other-namespace/org-secret' from annotation tekton.dev/status-secret is not in the run's namespace 'test-namespace'"},
+		{"invalid secret name", map[string]string{StatusSecretName: "Org_Secret"},
+			"invalid secret name 'Org_Secret' from annotation tekton.dev/status-secret"},
+		{"missing key", map[string]string{StatusSecretName: "org-secret", StatusSecretKeyName: "missing"},
+			"secret 'org-secret' does not have a 'missing' key \\(from annotation tekton.dev/status-secret-key\\)"},
+		{"missing key in the default secret", map[string]string{StatusSecretKeyName: "missing"},
+			"secret 'commit-status-tracker-git-secret' does not have a 'missing' key \\(from annotation tekton.dev/status-secret-key\\)"},
+		{"empty key", map[string]string{StatusSecretKeyName: ""},
+			"empty key from annotation tekton.dev/status-secret-key"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := fakeObject{annotations: tt.annotations, details: RunDetails{Namespace: "test-namespace"}}
+			_, err := GetAuthSecretForRun(cl, run, testCommit, nil)
+			if !test.MatchError(t, tt.wantErr, err) {
+				t.Fatalf("failed to match error: got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
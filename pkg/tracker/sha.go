@@ -0,0 +1,29 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/jenkins-x/go-scm/scm"
+)
+
+var shaRegexp = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// ResolveSHA returns the SHA of the commit, if the commit's Ref is a branch
+// or tag, it's resolved with the Git hosting service.
+//
+// The response is returned so that rejected credentials can be detected.
+func ResolveSHA(ctx context.Context, client *scm.Client, repo string, c *Commit) (string, *scm.Response, error) {
+	if shaRegexp.MatchString(c.Ref) {
+		return c.Ref, nil, nil
+	}
+	commit, res, err := client.Git.FindCommit(ctx, repo, c.Ref)
+	if err != nil {
+		return "", res, fmt.Errorf("failed to resolve ref '%s' in %s: %w", c.Ref, repo, err)
+	}
+	if commit == nil || commit.Sha == "" {
+		return "", res, fmt.Errorf("failed to resolve ref '%s' in %s: commit not found", c.Ref, repo)
+	}
+	return commit.Sha, res, nil
+}
@@ -0,0 +1,44 @@
+package tracker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	fakescm "github.com/jenkins-x/go-scm/scm/driver/fake"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+)
+
+func TestResolveSHA(t *testing.T) {
+	client, data := fakescm.NewDefault()
+	data.Commits["master"] = &scm.Commit{Sha: "e1466db56110fa1b813277c1647e20283d3370c3"}
+	resolveTests := []struct {
+		ref  string
+		want string
+	}{
+		{"master", "e1466db56110fa1b813277c1647e20283d3370c3"},
+		{"a6b3dd2e3d1a11eab77f2e728ce881255aca1e26", "a6b3dd2e3d1a11eab77f2e728ce881255aca1e26"},
+	}
+
+	for _, tt := range resolveTests {
+		t.Run(tt.ref, func(t *testing.T) {
+			sha, _, err := ResolveSHA(context.TODO(), client, "tektoncd/triggers", &Commit{Ref: tt.ref})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sha != tt.want {
+				t.Fatalf("ResolveSHA() got %s, want %s", sha, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSHAWithUnknownRef(t *testing.T) {
+	client, _ := fakescm.NewDefault()
+
+	_, _, err := ResolveSHA(context.TODO(), client, "tektoncd/triggers", &Commit{Ref: "develop"})
+	if !test.MatchError(t, "failed to resolve ref 'develop' in tektoncd/triggers: commit not found", err) {
+		t.Fatalf("failed to match error: got %s", err)
+	}
+}
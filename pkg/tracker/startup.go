@@ -0,0 +1,162 @@
+package tracker
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var startupLog = logf.Log.WithName("startup")
+
+// maxStartupRetries is the number of times that a run that fails to reconcile
+// at startup is retried before it's dropped.
+const maxStartupRetries = 3
+
+// UpdatedRun identifies a notifiable run, and when it was last updated.
+type UpdatedRun struct {
+	types.NamespacedName
+	Updated time.Time
+}
+
+// UpdatedRunLister returns the notifiable runs that were updated after the
+// provided time.
+type UpdatedRunLister func(ctx context.Context, since time.Time) ([]UpdatedRun, error)
+
+// StartupReconciler reconciles the runs that were updated within the
+// StartupWindow when the operator starts, catching up with runs that
+// completed while it wasn't running.
+//
+// Runs are reconciled newest first, from a separate rate-limited queue, so
+// that catching up doesn't flood the Git hosting service, or starve the
+// reconciliation of live runs.
+type StartupReconciler struct {
+	reconciler reconcile.Reconciler
+	list       UpdatedRunLister
+	limiter    *rate.Limiter
+	queue      workqueue.RateLimitingInterface
+	options    Options
+	clock      func() time.Time
+}
+
+// NewStartupReconciler creates a StartupReconciler that reconciles the runs
+// returned by the lister with the provided reconciler.
+func NewStartupReconciler(name string, r reconcile.Reconciler, l UpdatedRunLister, opts Options) *StartupReconciler {
+	limiter := rate.NewLimiter(rate.Limit(opts.StartupRate), opts.StartupBurst)
+	retries := workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond*5, time.Minute),
+		&workqueue.BucketRateLimiter{Limiter: limiter},
+	)
+	return &StartupReconciler{
+		reconciler: r,
+		list:       l,
+		limiter:    limiter,
+		queue:      workqueue.NewNamedRateLimitingQueue(retries, name),
+		options:    opts,
+		clock:      time.Now,
+	}
+}
+
+// Start implements manager.Runnable, it queues the runs updated within the
+// StartupWindow, and returns when they have all been reconciled, or the stop
+// channel is closed.
+func (s *StartupReconciler) Start(stop <-chan struct{}) error {
+	defer s.queue.ShutDown()
+	runs, err := s.Outstanding(context.Background())
+	if err != nil {
+		startupLog.Error(err, "failed to list runs to reconcile at startup")
+		return nil
+	}
+	startupLog.Info("reconciling runs at startup", "runs", len(runs))
+	for _, r := range runs {
+		s.queue.AddAfter(reconcile.Request{NamespacedName: r.NamespacedName}, s.limiter.Reserve().Delay())
+	}
+
+	go func() {
+		<-stop
+		s.queue.ShutDown()
+	}()
+	for remaining := len(runs); remaining > 0; {
+		done, ok := s.processNext()
+		if !ok {
+			break
+		}
+		if done {
+			remaining--
+		}
+	}
+	startupLog.Info("finished reconciling runs at startup")
+	return nil
+}
+
+// Outstanding returns the runs updated within the StartupWindow, newest
+// first.
+func (s *StartupReconciler) Outstanding(ctx context.Context) ([]UpdatedRun, error) {
+	runs, err := s.list(ctx, s.clock().Add(-s.options.StartupWindow))
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Updated.After(runs[j].Updated)
+	})
+	return runs, nil
+}
+
+// processNext reconciles the next run in the queue, it returns true if the
+// run doesn't need to be retried, and false if the queue was shutdown.
+func (s *StartupReconciler) processNext() (bool, bool) {
+	item, shutdown := s.queue.Get()
+	if shutdown {
+		return false, false
+	}
+	defer s.queue.Done(item)
+
+	req := item.(reconcile.Request)
+	if _, err := s.reconciler.Reconcile(req); err != nil {
+		if s.queue.NumRequeues(item) < maxStartupRetries {
+			s.queue.AddRateLimited(item)
+			return false, true
+		}
+		startupLog.Error(err, "failed to reconcile run at startup", "namespace", req.Namespace, "name", req.Name)
+	}
+	s.queue.Forget(item)
+	return true, true
+}
+
+// LiveEvents filters out the events that are handled by the
+// StartupReconciler, runs created before the operator started are reported in
+// the initial listing of runs, and periodic resyncs of the cache, neither of
+// which should be reconciled as live runs.
+//
+// Runs that were deleted while the operator wasn't running are also left to
+// the StartupReconciler, which lists them whenever they were updated.
+func LiveEvents(started time.Time) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return !e.Meta.GetCreationTimestamp().Time.Before(started)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaOld.GetResourceVersion() != e.MetaNew.GetResourceVersion()
+		},
+	}
+}
+
+// LatestTime returns the most recent of the provided times, ignoring any
+// that are nil.
+func LatestTime(times ...*metav1.Time) time.Time {
+	latest := time.Time{}
+	for _, t := range times {
+		if t != nil && t.Time.After(latest) {
+			latest = t.Time
+		}
+	}
+	return latest
+}
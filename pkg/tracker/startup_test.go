@@ -0,0 +1,151 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	tb "github.com/tektoncd/pipeline/test/builder"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testStartupOptions = Options{StartupWindow: time.Hour, StartupRate: 1000, StartupBurst: 10}
+
+func TestStartupReconcilerReconcilesNewestFirst(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	var since time.Time
+	lister := func(ctx context.Context, t time.Time) ([]UpdatedRun, error) {
+		since = t
+		return []UpdatedRun{
+			makeUpdatedRun("middle", now.Add(-time.Minute*20)),
+			makeUpdatedRun("oldest", now.Add(-time.Minute*50)),
+			makeUpdatedRun("newest", now.Add(-time.Minute)),
+		}, nil
+	}
+	r := &recordingReconciler{}
+	s := NewStartupReconciler(t.Name(), r, lister, testStartupOptions)
+	s.clock = func() time.Time { return now }
+
+	if err := s.Start(make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := now.Add(-time.Hour); !since.Equal(want) {
+		t.Errorf("lister called with %v, want %v", since, want)
+	}
+	if want := []string{"newest", "middle", "oldest"}; !reflect.DeepEqual(r.names(), want) {
+		t.Fatalf("reconciled %#v, want %#v", r.names(), want)
+	}
+}
+
+func TestStartupReconcilerRetriesFailedRuns(t *testing.T) {
+	lister := func(ctx context.Context, t time.Time) ([]UpdatedRun, error) {
+		return []UpdatedRun{
+			makeUpdatedRun("failing", time.Now()),
+			makeUpdatedRun("flaky", time.Now().Add(-time.Minute)),
+		}, nil
+	}
+	r := &recordingReconciler{failures: map[string]int{"failing": 10, "flaky": 1}}
+	s := NewStartupReconciler(t.Name(), r, lister, testStartupOptions)
+
+	if err := s.Start(make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"failing", "flaky", "failing", "flaky", "failing", "failing"}
+	if !reflect.DeepEqual(r.names(), want) {
+		t.Fatalf("reconciled %#v, want %#v", r.names(), want)
+	}
+}
+
+func TestStartupReconcilerStops(t *testing.T) {
+	lister := func(ctx context.Context, t time.Time) ([]UpdatedRun, error) {
+		return []UpdatedRun{makeUpdatedRun("first", time.Now()), makeUpdatedRun("second", time.Now())}, nil
+	}
+	s := NewStartupReconciler(t.Name(), &recordingReconciler{}, lister, Options{StartupWindow: time.Hour, StartupRate: 0.001, StartupBurst: 1})
+	stop := make(chan struct{})
+	close(stop)
+
+	done := make(chan error)
+	go func() {
+		done <- s.Start(stop)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("startup reconciler did not stop")
+	}
+}
+
+func TestLiveEvents(t *testing.T) {
+	started := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	p := LiveEvents(started)
+
+	before := tb.PipelineRun("before", "foo")
+	before.CreationTimestamp = metav1.NewTime(started.Add(-time.Minute))
+	after := tb.PipelineRun("after", "foo")
+	after.CreationTimestamp = metav1.NewTime(started.Add(time.Minute))
+	if p.Create(event.CreateEvent{Meta: before, Object: before}) {
+		t.Error("create event for a run created before starting was not filtered")
+	}
+	if !p.Create(event.CreateEvent{Meta: after, Object: after}) {
+		t.Error("create event for a run created after starting was filtered")
+	}
+
+	updated := before.DeepCopy()
+	updated.ResourceVersion = "2"
+	if p.Update(event.UpdateEvent{MetaOld: before, ObjectOld: before, MetaNew: before, ObjectNew: before}) {
+		t.Error("update event for a resync was not filtered")
+	}
+	if !p.Update(event.UpdateEvent{MetaOld: before, ObjectOld: before, MetaNew: updated, ObjectNew: updated}) {
+		t.Error("update event for a changed run was filtered")
+	}
+}
+
+func TestLatestTime(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	latest := LatestTime(&metav1.Time{Time: now.Add(-time.Hour)}, nil, &metav1.Time{Time: now}, &metav1.Time{Time: now.Add(-time.Minute)})
+	if !latest.Equal(now) {
+		t.Fatalf("LatestTime() got %v, want %v", latest, now)
+	}
+	if l := LatestTime(nil); !l.IsZero() {
+		t.Fatalf("LatestTime(nil) got %v, want zero", l)
+	}
+}
+
+type recordingReconciler struct {
+	requests []reconcile.Request
+	failures map[string]int
+}
+
+func (r *recordingReconciler) Reconcile(req reconcile.Request) (reconcile.Result, error) {
+	r.requests = append(r.requests, req)
+	if r.failures[req.Name] > 0 {
+		r.failures[req.Name]--
+		return reconcile.Result{}, errors.New("failed")
+	}
+	return reconcile.Result{}, nil
+}
+
+func (r *recordingReconciler) names() []string {
+	names := []string{}
+	for _, req := range r.requests {
+		names = append(names, req.Name)
+	}
+	return names
+}
+
+func makeUpdatedRun(name string, updated time.Time) UpdatedRun {
+	return UpdatedRun{
+		NamespacedName: types.NamespacedName{Namespace: "test-namespace", Name: name},
+		Updated:        updated,
+	}
+}
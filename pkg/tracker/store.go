@@ -0,0 +1,184 @@
+package tracker
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Store records the last State that was reported for a key, the keys are
+// owned by runs, and can be forgotten when the run is deleted.
+type Store interface {
+	Get(key string) (State, bool)
+	Set(uid types.UID, key string, s State)
+	Forget(uid types.UID)
+	Len() int
+}
+
+type storeEntry struct {
+	uid     types.UID
+	key     string
+	state   State
+	updated time.Time
+	// expiry is the entry's element in the list of terminal entries, or nil
+	// if the entry is pending.
+	expiry *list.Element
+}
+
+// lruStore is a Store that holds at most size entries, evicting the least
+// recently used entry when full.
+//
+// Entries for terminal states expire after the TTL, pending entries remain
+// until the run is forgotten, or they are evicted.
+//
+// Terminal entries are also kept in the order that they were updated, and
+// the entries are indexed by their owner, and the number of pending entries
+// is counted as they change, so that expiring, forgetting and the metrics
+// don't need to check every entry.
+type lruStore struct {
+	sync.Mutex
+	name     string
+	size     int
+	ttl      time.Duration
+	clock    func() time.Time
+	entries  *list.List
+	keys     map[string]*list.Element
+	owned    map[types.UID]map[string]*list.Element
+	terminal *list.List
+	pending  int
+}
+
+// NewStore creates a Store that holds at most size entries, terminal states
+// are expired after the ttl.
+//
+// The name is used to identify the store in metrics.
+func NewStore(name string, size int, ttl time.Duration) Store {
+	return &lruStore{
+		name:     name,
+		size:     size,
+		ttl:      ttl,
+		clock:    time.Now,
+		entries:  list.New(),
+		keys:     make(map[string]*list.Element),
+		owned:    make(map[types.UID]map[string]*list.Element),
+		terminal: list.New(),
+	}
+}
+
+// Get returns the last state that was recorded for the key.
+func (s *lruStore) Get(key string) (State, bool) {
+	s.Lock()
+	defer s.Unlock()
+	el, ok := s.keys[key]
+	if !ok {
+		return Pending, false
+	}
+	e := el.Value.(*storeEntry)
+	if s.expired(e) {
+		s.remove(el)
+		s.updateMetrics()
+		return Pending, false
+	}
+	s.entries.MoveToFront(el)
+	return e.state, true
+}
+
+// Set records the state for the key, owned by the run with the uid.
+func (s *lruStore) Set(uid types.UID, key string, st State) {
+	s.Lock()
+	defer s.Unlock()
+	defer s.updateMetrics()
+	s.removeExpired()
+	el, ok := s.keys[key]
+	if ok {
+		s.entries.MoveToFront(el)
+	} else {
+		el = s.entries.PushFront(&storeEntry{uid: uid, key: key, state: Pending})
+		s.keys[key] = el
+		if s.owned[uid] == nil {
+			s.owned[uid] = make(map[string]*list.Element)
+		}
+		s.owned[uid][key] = el
+		s.pending++
+	}
+	s.update(el, st)
+	for s.entries.Len() > s.size {
+		s.remove(s.entries.Back())
+	}
+}
+
+// Forget removes all the entries owned by the run with the uid.
+func (s *lruStore) Forget(uid types.UID) {
+	s.Lock()
+	defer s.Unlock()
+	defer s.updateMetrics()
+	for _, el := range s.owned[uid] {
+		s.remove(el)
+	}
+}
+
+// Len returns the number of entries in the store.
+func (s *lruStore) Len() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.entries.Len()
+}
+
+func (s *lruStore) expired(e *storeEntry) bool {
+	return e.state != Pending && s.clock().Sub(e.updated) > s.ttl
+}
+
+// removeExpired removes the expired entries, from the least recently updated
+// terminal entry, until one that hasn't expired.
+func (s *lruStore) removeExpired() {
+	for el := s.terminal.Front(); el != nil; el = s.terminal.Front() {
+		entry := el.Value.(*list.Element)
+		if !s.expired(entry.Value.(*storeEntry)) {
+			return
+		}
+		s.remove(entry)
+	}
+}
+
+// update records the state in the entry, keeping the list of terminal
+// entries and the pending count up to date.
+func (s *lruStore) update(el *list.Element, st State) {
+	e := el.Value.(*storeEntry)
+	if e.state == Pending {
+		s.pending--
+	}
+	if e.expiry != nil {
+		s.terminal.Remove(e.expiry)
+		e.expiry = nil
+	}
+	e.state = st
+	e.updated = s.clock()
+	if st == Pending {
+		s.pending++
+		return
+	}
+	e.expiry = s.terminal.PushBack(el)
+}
+
+func (s *lruStore) remove(el *list.Element) {
+	e := el.Value.(*storeEntry)
+	s.entries.Remove(el)
+	delete(s.keys, e.key)
+	delete(s.owned[e.uid], e.key)
+	if len(s.owned[e.uid]) == 0 {
+		delete(s.owned, e.uid)
+	}
+	if e.expiry != nil {
+		s.terminal.Remove(e.expiry)
+	}
+	if e.state == Pending {
+		s.pending--
+	}
+}
+
+func (s *lruStore) updateMetrics() {
+	trackedRuns.WithLabelValues(s.name).Set(float64(s.entries.Len()))
+	pendingRuns.WithLabelValues(s.name).Set(float64(s.pending))
+}
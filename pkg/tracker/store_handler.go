@@ -0,0 +1,22 @@
+package tracker
+
+import (
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// ForgetOnDelete is an EventHandler that enqueues requests for runs, and
+// removes deleted runs from the Store.
+type ForgetOnDelete struct {
+	handler.EnqueueRequestForObject
+	Store Store
+}
+
+// Delete implements handler.EventHandler.
+func (f *ForgetOnDelete) Delete(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	if e.Meta != nil {
+		f.Store.Forget(e.Meta.GetUID())
+	}
+	f.EnqueueRequestForObject.Delete(e, q)
+}
@@ -0,0 +1,174 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	tb "github.com/tektoncd/pipeline/test/builder"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestStoreGetAndSet(t *testing.T) {
+	s := NewStore("test", 10, time.Hour)
+
+	if _, ok := s.Get("key1"); ok {
+		t.Fatal("Get() found a state in an empty store")
+	}
+	s.Set("uid1", "key1", Pending)
+	s.Set("uid1", "key1", Successful)
+
+	assertStoreState(t, s, "key1", Successful)
+	if l := s.Len(); l != 1 {
+		t.Fatalf("Len() got %d, want 1", l)
+	}
+}
+
+func TestStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewStore("test", 2, time.Hour)
+	s.Set("uid1", "key1", Pending)
+	s.Set("uid2", "key2", Pending)
+	// Using key1 makes key2 the least recently used.
+	s.Get("key1")
+	s.Set("uid3", "key3", Pending)
+
+	if _, ok := s.Get("key2"); ok {
+		t.Fatal("least recently used key was not evicted")
+	}
+	assertStoreState(t, s, "key1", Pending)
+	assertStoreState(t, s, "key3", Pending)
+}
+
+func TestStoreExpiresTerminalStates(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	s := NewStore("test", 10, time.Hour).(*lruStore)
+	s.clock = func() time.Time { return now }
+	s.Set("uid1", "pending", Pending)
+	s.Set("uid2", "successful", Successful)
+	s.Set("uid3", "failed", Failed)
+
+	now = now.Add(time.Minute * 30)
+	assertStoreState(t, s, "successful", Successful)
+
+	now = now.Add(time.Minute * 31)
+	s.Set("uid4", "recent", Successful)
+	if l := s.Len(); l != 2 {
+		t.Fatalf("Len() got %d, want 2", l)
+	}
+	assertStoreState(t, s, "pending", Pending)
+	assertStoreState(t, s, "recent", Successful)
+	if _, ok := s.Get("failed"); ok {
+		t.Fatal("expired state was not removed")
+	}
+}
+
+func TestStoreForget(t *testing.T) {
+	s := NewStore("test", 10, time.Hour)
+	s.Set("uid1", "key1", Pending)
+	s.Set("uid1", "key2", Pending)
+	s.Set("uid2", "key3", Pending)
+
+	s.Forget("uid1")
+
+	if l := s.Len(); l != 1 {
+		t.Fatalf("Len() got %d, want 1", l)
+	}
+	assertStoreState(t, s, "key3", Pending)
+}
+
+func TestStoreMetrics(t *testing.T) {
+	s := NewStore("metrics-test", 10, time.Hour)
+	s.Set("uid1", "key1", Pending)
+	s.Set("uid2", "key2", Pending)
+	assertStoreMetric(t, "metrics-test", 2)
+
+	s.Forget("uid1")
+	assertStoreMetric(t, "metrics-test", 1)
+}
+
+func TestStorePendingMetric(t *testing.T) {
+	s := NewStore("pending-test", 10, time.Hour)
+	s.Set("uid1", "key1", Pending)
+	s.Set("uid2", "key2", Pending)
+	s.Set("uid3", "key3", Failed)
+	assertPendingMetric(t, "pending-test", 2)
+
+	s.Set("uid1", "key1", Successful)
+	assertPendingMetric(t, "pending-test", 1)
+
+	s.Forget("uid2")
+	assertPendingMetric(t, "pending-test", 0)
+}
+
+func TestStoreMetricsWhenExpired(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	s := NewStore("expired-test", 10, time.Hour).(*lruStore)
+	s.clock = func() time.Time { return now }
+	s.Set("uid1", "key1", Pending)
+	s.Set("uid2", "key2", Successful)
+	// A retried run goes back to pending.
+	s.Set("uid1", "key1", Failed)
+	s.Set("uid1", "key1", Pending)
+	assertStoreMetric(t, "expired-test", 2)
+	assertPendingMetric(t, "expired-test", 1)
+
+	now = now.Add(time.Minute * 61)
+	if _, ok := s.Get("key2"); ok {
+		t.Fatal("expired state was not removed")
+	}
+	assertStoreMetric(t, "expired-test", 1)
+	assertPendingMetric(t, "expired-test", 1)
+
+	s.Forget("uid1")
+	assertStoreMetric(t, "expired-test", 0)
+	assertPendingMetric(t, "expired-test", 0)
+	if l := s.terminal.Len(); l != 0 {
+		t.Fatalf("got %d terminal entries, want 0", l)
+	}
+}
+
+func TestForgetOnDelete(t *testing.T) {
+	s := NewStore("test", 10, time.Hour)
+	s.Set("uid1", "key1", Pending)
+	pr := tb.PipelineRun("test-pipeline-run", "foo")
+	pr.UID = types.UID("uid1")
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+
+	h := &ForgetOnDelete{Store: s}
+	h.Delete(event.DeleteEvent{Meta: pr, Object: pr}, q)
+
+	if l := s.Len(); l != 0 {
+		t.Fatalf("deleted run was not forgotten, got %d entries", l)
+	}
+	if l := q.Len(); l != 1 {
+		t.Fatalf("deleted run was not enqueued, got %d requests", l)
+	}
+}
+
+func assertStoreState(t *testing.T, s Store, key string, want State) {
+	t.Helper()
+	st, ok := s.Get(key)
+	if !ok {
+		t.Fatalf("Get(%#v) found no state", key)
+	}
+	if st != want {
+		t.Fatalf("Get(%#v) got %v, want %v", key, st, want)
+	}
+}
+
+func assertStoreMetric(t *testing.T, name string, want float64) {
+	t.Helper()
+	if v := testutil.ToFloat64(trackedRuns.WithLabelValues(name)); v != want {
+		t.Fatalf("store size metric got %v, want %v", v, want)
+	}
+}
+
+func assertPendingMetric(t *testing.T, name string, want float64) {
+	t.Helper()
+	if v := testutil.ToFloat64(pendingRuns.WithLabelValues(name)); v != want {
+		t.Fatalf("pending runs metric got %v, want %v", v, want)
+	}
+}
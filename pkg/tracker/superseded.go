@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jenkins-x/go-scm/scm"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IsNewer returns true if run a is newer than run b, where they report the
+// same context for a commit.
+//
+// If both runs have a StatusGenerationName annotation, the run with the
+// higher generation is newer, otherwise the run that was created most
+// recently is newer, runs created at the same time are ordered by name.
+func IsNewer(a, b metav1.Object) bool {
+	ga, aok := generation(a)
+	gb, bok := generation(b)
+	if aok && bok && ga != gb {
+		return ga > gb
+	}
+	ca, cb := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !ca.Equal(&cb) {
+		return cb.Before(&ca)
+	}
+	return a.GetName() > b.GetName()
+}
+
+func generation(o metav1.Object) (int64, bool) {
+	v, ok := o.GetAnnotations()[StatusGenerationName]
+	if !ok {
+		return 0, false
+	}
+	g, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return g, true
+}
+
+// Branch returns the branch or pull request that the run was for, or "" if
+// the run has no StatusBranchName annotation.
+func Branch(o metav1.Object) string {
+	return o.GetAnnotations()[StatusBranchName]
+}
+
+// SupersededStatusInput returns the status to report for a context, when a
+// run is cancelled because a run for a newer commit has superseded it.
+func SupersededStatusInput(context, newer string) *scm.StatusInput {
+	return &scm.StatusInput{
+		State: scm.StateError,
+		Label: context,
+		Desc:  fmt.Sprintf("superseded by %s", newer),
+	}
+}
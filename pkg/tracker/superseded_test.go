@@ -0,0 +1,53 @@
+package tracker
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsNewer(t *testing.T) {
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	newerTests := []struct {
+		name string
+		a    metav1.Object
+		b    metav1.Object
+		want bool
+	}{
+		{"created later", makeRunMeta("a", now, ""), makeRunMeta("b", now.Add(-time.Minute), ""), true},
+		{"created earlier", makeRunMeta("a", now.Add(-time.Minute), ""), makeRunMeta("b", now, ""), false},
+		{"created at the same time", makeRunMeta("b", now, ""), makeRunMeta("a", now, ""), true},
+		{"higher generation", makeRunMeta("a", now.Add(-time.Minute), "2"), makeRunMeta("b", now, "1"), true},
+		{"lower generation", makeRunMeta("a", now, "1"), makeRunMeta("b", now.Add(-time.Minute), "2"), false},
+		{"same generation", makeRunMeta("a", now, "1"), makeRunMeta("b", now.Add(-time.Minute), "1"), true},
+		{"one generation", makeRunMeta("a", now, "1"), makeRunMeta("b", now.Add(-time.Minute), ""), true},
+		{"invalid generation", makeRunMeta("a", now, "2"), makeRunMeta("b", now.Add(-time.Minute), "one"), true},
+	}
+
+	for _, tt := range newerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b := IsNewer(tt.a, tt.b); b != tt.want {
+				t.Fatalf("IsNewer() got %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranch(t *testing.T) {
+	m := &metav1.ObjectMeta{Annotations: map[string]string{StatusBranchName: "refs/pull/42/head"}}
+	if b := Branch(m); b != "refs/pull/42/head" {
+		t.Fatalf("Branch() got %#v, want %#v", b, "refs/pull/42/head")
+	}
+	if b := Branch(&metav1.ObjectMeta{}); b != "" {
+		t.Fatalf("Branch() got %#v, want \"\"", b)
+	}
+}
+
+func makeRunMeta(name string, created time.Time, generation string) metav1.Object {
+	m := &metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)}
+	if generation != "" {
+		m.Annotations = map[string]string{StatusGenerationName: generation}
+	}
+	return m
+}
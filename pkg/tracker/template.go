@@ -0,0 +1,33 @@
+package tracker
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// renderAnnotation finds the named annotation, and executes it as a
+// text/template with the RunDetails.
+//
+// If the annotation is not present, the default is returned without being
+// executed.
+func renderAnnotation(r trackableResource, name, def string, d RunDetails) (string, error) {
+	v, ok := r.Annotations()[name]
+	if !ok {
+		return def, nil
+	}
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse annotation %s: %w", name, err)
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, d); err != nil {
+		return "", fmt.Errorf("failed to execute annotation %s: %w", name, err)
+	}
+	return b.String(), nil
+}
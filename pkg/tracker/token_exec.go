@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenHelperTimeout is how long the token helper can run for.
+	tokenHelperTimeout = time.Second * 30
+
+	// tokenExpirySkew is how long before a token expires that it's fetched
+	// again.
+	tokenExpirySkew = time.Minute
+)
+
+// ExecTokenProvider runs a credential helper to get tokens.
+//
+// The helper is run with the host as its argument, and the repository in the
+// COMMIT_STATUS_REPO environment variable, and must write a JSON object to
+// stdout, e.g.
+//
+//	{"token": "abc123", "expiry": "2020-01-10T12:00:00Z"}
+//
+// Tokens are reused for the host and repository until shortly before they
+// expire, tokens without an expiry are not reused.
+type ExecTokenProvider struct {
+	command string
+	clock   func() time.Time
+
+	mu     sync.Mutex
+	tokens map[string]helperToken
+}
+
+type helperToken struct {
+	Token  string     `json:"token"`
+	Expiry *time.Time `json:"expiry,omitempty"`
+}
+
+// NewExecTokenProvider creates an ExecTokenProvider that runs the command.
+func NewExecTokenProvider(command string) *ExecTokenProvider {
+	return &ExecTokenProvider{command: command, clock: time.Now, tokens: make(map[string]helperToken)}
+}
+
+// Token implements TokenProvider.
+func (e *ExecTokenProvider) Token(ctx context.Context, r Trackable, c *Commit) (string, error) {
+	return e.token(ctx, c, false)
+}
+
+// Refresh implements TokenProvider.
+func (e *ExecTokenProvider) Refresh(ctx context.Context, r Trackable, c *Commit) (string, error) {
+	return e.token(ctx, c, true)
+}
+
+func (e *ExecTokenProvider) token(ctx context.Context, c *Commit, refresh bool) (string, error) {
+	host, err := c.Host()
+	if err != nil {
+		return "", err
+	}
+	repo, err := c.Repo()
+	if err != nil {
+		return "", err
+	}
+
+	key := host + "/" + repo
+	e.mu.Lock()
+	cached, ok := e.tokens[key]
+	e.mu.Unlock()
+	if ok && !refresh && e.clock().Add(tokenExpirySkew).Before(*cached.Expiry) {
+		return cached.Token, nil
+	}
+
+	t, err := e.run(ctx, host, repo)
+	if err != nil {
+		return "", err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if t.Expiry != nil {
+		e.tokens[key] = t
+	} else {
+		delete(e.tokens, key)
+	}
+	return t.Token, nil
+}
+
+func (e *ExecTokenProvider) run(ctx context.Context, host, repo string) (helperToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, tokenHelperTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.command, host)
+	cmd.Env = append(os.Environ(), "COMMIT_STATUS_REPO="+repo)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return helperToken{}, fmt.Errorf("failed to get a token, token helper failed for host '%s': %w: %s", host, err, strings.TrimSpace(stderr.String()))
+	}
+	var t helperToken
+	if err := json.Unmarshal(stdout.Bytes(), &t); err != nil {
+		return helperToken{}, fmt.Errorf("failed to get a token, invalid token helper output for host '%s': %w", host, err)
+	}
+	if t.Token == "" {
+		return helperToken{}, fmt.Errorf("failed to get a token, token helper returned no token for host '%s'", host)
+	}
+	return t, nil
+}
@@ -0,0 +1,113 @@
+package tracker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+)
+
+func TestExecTokenProvider(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	p := NewExecTokenProvider(writeHelper(t, dir,
+		`echo "{\"token\": \"token-for-$1-$COMMIT_STATUS_REPO\", \"expiry\": \"2020-01-10T13:00:00Z\"}"`))
+	now := time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC)
+	p.clock = func() time.Time { return now }
+
+	assertToken(t, p, "token-for-github.com-tektoncd/triggers")
+	assertToken(t, p, "token-for-github.com-tektoncd/triggers")
+	assertHelperCalls(t, dir, 1)
+
+	now = now.Add(time.Minute * 59)
+	assertToken(t, p, "token-for-github.com-tektoncd/triggers")
+	assertHelperCalls(t, dir, 2)
+
+	if _, err := p.Refresh(context.TODO(), fakeObject{}, testCommit); err != nil {
+		t.Fatal(err)
+	}
+	assertHelperCalls(t, dir, 3)
+}
+
+func TestExecTokenProviderWithoutExpiry(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	p := NewExecTokenProvider(writeHelper(t, dir, `echo '{"token": "helper-token"}'`))
+
+	assertToken(t, p, "helper-token")
+	assertToken(t, p, "helper-token")
+	assertHelperCalls(t, dir, 2)
+}
+
+func TestExecTokenProviderErrors(t *testing.T) {
+	errorTests := []struct {
+		name    string
+		script  string
+		wantErr string
+	}{
+		{"helper fails", `echo "no credentials for $1" >&2; exit 1`,
+			"token helper failed for host 'github.com': exit status 1: no credentials for github.com"},
+		{"invalid output", `echo "helper-token"`,
+			"invalid token helper output for host 'github.com'"},
+		{"no token", `echo '{"expiry": "2020-01-10T13:00:00Z"}'`,
+			"token helper returned no token for host 'github.com'"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := makeTempDir(t)
+			defer os.RemoveAll(dir)
+			p := NewExecTokenProvider(writeHelper(t, dir, tt.script))
+
+			_, err := p.Token(context.TODO(), fakeObject{}, testCommit)
+			if !test.MatchError(t, tt.wantErr, err) {
+				t.Fatalf("failed to match error: got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// writeHelper writes a token helper script to the directory, that records
+// each time it's called.
+func writeHelper(t *testing.T, dir, script string) string {
+	t.Helper()
+	path := filepath.Join(dir, "token-helper")
+	writeFile(t, path, "#!/bin/sh\necho called >> \""+dir+"/calls\"\n"+script+"\n")
+	return path
+}
+
+func assertHelperCalls(t *testing.T, dir string, want int) {
+	t.Helper()
+	b, err := ioutil.ReadFile(filepath.Join(dir, "calls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls := strings.Count(string(b), "called"); calls != want {
+		t.Fatalf("token helper called %d times, want %d", calls, want)
+	}
+}
+
+func TestExecTokenProviderCachesTokensForEachRepo(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	p := NewExecTokenProvider(writeHelper(t, dir,
+		`echo "{\"token\": \"token-for-$COMMIT_STATUS_REPO\", \"expiry\": \"2020-01-10T13:00:00Z\"}"`))
+	p.clock = func() time.Time { return time.Date(2020, time.January, 10, 12, 0, 0, 0, time.UTC) }
+	other := &Commit{RepoURL: "https://github.com/tektoncd/pipeline", Ref: "master"}
+
+	assertToken(t, p, "token-for-tektoncd/triggers")
+	token, err := p.Token(context.TODO(), fakeObject{}, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "token-for-tektoncd/pipeline" {
+		t.Fatalf("Token() got %s, want token-for-tektoncd/pipeline", token)
+	}
+	assertToken(t, p, "token-for-tektoncd/triggers")
+	assertHelperCalls(t, dir, 2)
+}
@@ -0,0 +1,78 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileTokenProvider reads tokens from files, usually mounted from a secret
+// store, with a file for each host.
+//
+// The files are stat'ed each time a token is needed, and only read again when
+// their modification time or size changes.
+type FileTokenProvider struct {
+	paths map[string]string
+
+	mu    sync.Mutex
+	files map[string]tokenFile
+}
+
+type tokenFile struct {
+	modTime time.Time
+	size    int64
+	token   string
+}
+
+// NewFileTokenProvider creates a FileTokenProvider that reads the token for
+// each host from the path for the host.
+func NewFileTokenProvider(paths map[string]string) *FileTokenProvider {
+	return &FileTokenProvider{paths: paths, files: make(map[string]tokenFile)}
+}
+
+// Token implements TokenProvider.
+func (f *FileTokenProvider) Token(ctx context.Context, r Trackable, c *Commit) (string, error) {
+	return f.token(c, false)
+}
+
+// Refresh implements TokenProvider.
+func (f *FileTokenProvider) Refresh(ctx context.Context, r Trackable, c *Commit) (string, error) {
+	return f.token(c, true)
+}
+
+func (f *FileTokenProvider) token(c *Commit, refresh bool) (string, error) {
+	host, err := c.Host()
+	if err != nil {
+		return "", err
+	}
+	path, ok := f.paths[host]
+	if !ok {
+		return "", fmt.Errorf("failed to get a token, no token file for host '%s'", host)
+	}
+	// Stat follows the symlinks that mounted secrets are updated through.
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get a token, error reading token file for host '%s': %w", host, err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cached, ok := f.files[host]
+	if ok && !refresh && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.token, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get a token, error reading token file for host '%s': %w", host, err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("failed to get a token, token file for host '%s' is empty", host)
+	}
+	f.files[host] = tokenFile{modTime: info.ModTime(), size: info.Size(), token: token}
+	return token, nil
+}
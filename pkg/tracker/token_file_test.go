@@ -0,0 +1,112 @@
+package tracker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+)
+
+func TestFileTokenProvider(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "github")
+	writeFile(t, path, "first-token\n")
+	p := NewFileTokenProvider(map[string]string{"github.com": path})
+
+	assertToken(t, p, "first-token")
+
+	writeFile(t, path, "second-token")
+	// Make sure the modification time changes, whatever the resolution of
+	// the filesystem.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	assertToken(t, p, "second-token")
+}
+
+func TestFileTokenProviderRefresh(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "github")
+	writeFile(t, path, "first-token")
+	p := NewFileTokenProvider(map[string]string{"github.com": path})
+	assertToken(t, p, "first-token")
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "other-token")
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := p.Refresh(context.TODO(), fakeObject{}, testCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "other-token" {
+		t.Fatalf("Refresh() got %s, want %s", token, "other-token")
+	}
+}
+
+func TestFileTokenProviderErrors(t *testing.T) {
+	dir := makeTempDir(t)
+	defer os.RemoveAll(dir)
+	writeFile(t, filepath.Join(dir, "empty"), "\n")
+	errorTests := []struct {
+		name    string
+		paths   map[string]string
+		wantErr string
+	}{
+		{"no file for the host", map[string]string{"gitlab.com": filepath.Join(dir, "gitlab")},
+			"no token file for host 'github.com'"},
+		{"missing file", map[string]string{"github.com": filepath.Join(dir, "missing")},
+			"error reading token file for host 'github.com'"},
+		{"empty file", map[string]string{"github.com": filepath.Join(dir, "empty")},
+			"token file for host 'github.com' is empty"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewFileTokenProvider(tt.paths)
+			_, err := p.Token(context.TODO(), fakeObject{}, testCommit)
+			if !test.MatchError(t, tt.wantErr, err) {
+				t.Fatalf("failed to match error: got %s, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func assertToken(t *testing.T, p TokenProvider, want string) {
+	t.Helper()
+	token, err := p.Token(context.TODO(), fakeObject{}, testCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != want {
+		t.Fatalf("Token() got %s, want %s", token, want)
+	}
+}
+
+func makeTempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "tokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := ioutil.WriteFile(path, []byte(content), 0700); err != nil {
+		t.Fatal(err)
+	}
+}
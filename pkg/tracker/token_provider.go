@@ -0,0 +1,56 @@
+package tracker
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TokenProvider finds the token to report the statuses for a run's commit
+// with.
+type TokenProvider interface {
+	// Token returns the token for the run's commit.
+	Token(ctx context.Context, r Trackable, c *Commit) (string, error)
+
+	// Refresh returns the token for the run's commit, bypassing any caching,
+	// it's used when the Git hosting service rejects the token.
+	Refresh(ctx context.Context, r Trackable, c *Commit) (string, error)
+}
+
+// NewTokenProvider returns the TokenProvider configured by the options, a
+// TokenHelper is used if one is configured, then TokenFiles, and otherwise
+// Secrets are read with the client, and the reader when refreshing.
+func NewTokenProvider(c, reader client.Reader, opts Options) TokenProvider {
+	if opts.TokenHelper != "" {
+		return NewExecTokenProvider(opts.TokenHelper)
+	}
+	if len(opts.TokenFiles) > 0 {
+		return NewFileTokenProvider(opts.TokenFiles)
+	}
+	return NewSecretTokenProvider(c, reader, opts.CredentialsNamespace)
+}
+
+// SecretTokenProvider reads tokens from Kubernetes Secrets, see
+// GetAuthSecretForRun.
+type SecretTokenProvider struct {
+	client  client.Reader
+	reader  client.Reader
+	central *CentralCredentials
+}
+
+// NewSecretTokenProvider creates a SecretTokenProvider that reads Secrets
+// with the client, which is usually cached, and with the reader, which is
+// not, when refreshing, and for central credentials from credentialsNS.
+func NewSecretTokenProvider(c, reader client.Reader, credentialsNS string) *SecretTokenProvider {
+	return &SecretTokenProvider{client: c, reader: reader, central: NewCentralCredentials(reader, credentialsNS)}
+}
+
+// Token implements TokenProvider.
+func (s *SecretTokenProvider) Token(ctx context.Context, r Trackable, c *Commit) (string, error) {
+	return GetAuthSecretForRun(s.client, r, c, s.central)
+}
+
+// Refresh implements TokenProvider.
+func (s *SecretTokenProvider) Refresh(ctx context.Context, r Trackable, c *Commit) (string, error) {
+	return GetAuthSecretForRun(s.reader, r, c, s.central)
+}
@@ -0,0 +1,52 @@
+package tracker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewTokenProvider(t *testing.T) {
+	cl := fake.NewFakeClient()
+	files := map[string]string{"github.com": "/tokens/github"}
+	providerTests := []struct {
+		name string
+		opts Options
+		want TokenProvider
+	}{
+		{"secrets", Options{}, &SecretTokenProvider{}},
+		{"files", Options{TokenFiles: files}, &FileTokenProvider{}},
+		{"helper", Options{TokenFiles: files, TokenHelper: "/bin/token-helper"}, &ExecTokenProvider{}},
+	}
+
+	for _, tt := range providerTests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewTokenProvider(cl, cl, tt.opts)
+			if reflect.TypeOf(p) != reflect.TypeOf(tt.want) {
+				t.Fatalf("NewTokenProvider() got %T, want %T", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretTokenProviderReadsCentralCredentialsWithReader(t *testing.T) {
+	cached := fake.NewFakeClient()
+	reader := fake.NewFakeClient(
+		makeNamespace("test-namespace", map[string]string{"team": "ci"}),
+		makeCentralCredential("bot", "central-token", map[string]string{
+			CredentialOwnersName:            "tektoncd",
+			CredentialNamespaceSelectorName: "team=ci",
+		}))
+	p := NewSecretTokenProvider(cached, reader, "operators")
+	run := fakeObject{details: RunDetails{Namespace: "test-namespace"}}
+
+	token, err := p.Token(context.Background(), run, testCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "central-token" {
+		t.Fatalf("Token() got %s, want central-token", token)
+	}
+}
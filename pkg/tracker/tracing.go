@@ -0,0 +1,87 @@
+package tracker
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"contrib.go.opencensus.io/exporter/ocagent"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+)
+
+// These are the trace exporters that can be configured.
+const (
+	TraceExporterNone    = ""
+	TraceExporterOCAgent = "ocagent"
+)
+
+// TraceServiceName is the name of the service that spans are exported for.
+const TraceServiceName = "commit-status-tracker"
+
+// StartTracing registers the named trace exporter, and samples the fraction
+// of traces, the returned function flushes and stops the exporter.
+//
+// For the ocagent exporter, spans are sent to the OpenCensus Agent at the
+// address, or the agent's default address if it's empty.
+func StartTracing(exporter, address string, sampleRate float64) (func(), error) {
+	switch exporter {
+	case TraceExporterNone:
+		return func() {}, nil
+	case TraceExporterOCAgent:
+		opts := []ocagent.ExporterOption{ocagent.WithInsecure(), ocagent.WithServiceName(TraceServiceName)}
+		if address != "" {
+			opts = append(opts, ocagent.WithAddress(address))
+		}
+		e, err := ocagent.NewExporter(opts...)
+		if err != nil {
+			return nil, err
+		}
+		trace.RegisterExporter(e)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(sampleRate)})
+		return func() {
+			trace.UnregisterExporter(e)
+			e.Stop()
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+}
+
+// MemoryExporter is a trace.Exporter that keeps the spans that are exported
+// in memory, it's intended for tests.
+type MemoryExporter struct {
+	sync.Mutex
+	spans []*trace.SpanData
+}
+
+// ExportSpan implements trace.Exporter.
+func (e *MemoryExporter) ExportSpan(s *trace.SpanData) {
+	e.Lock()
+	defer e.Unlock()
+	e.spans = append(e.spans, s)
+}
+
+// Spans returns the spans that have been exported, in the order that they
+// ended.
+func (e *MemoryExporter) Spans() []*trace.SpanData {
+	e.Lock()
+	defer e.Unlock()
+	return append([]*trace.SpanData(nil), e.spans...)
+}
+
+// Span returns the last exported span with the name, or nil if there is none.
+func (e *MemoryExporter) Span(name string) *trace.SpanData {
+	spans := e.Spans()
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].Name == name {
+			return spans[i]
+		}
+	}
+	return nil
+}
+
+// tracingTransport creates a span for each request to the Git hosting
+// service, and propagates the trace context in the request headers.
+func tracingTransport(base http.RoundTripper) http.RoundTripper {
+	return &ochttp.Transport{Base: base}
+}
@@ -0,0 +1,61 @@
+package tracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opencensus.io/trace"
+
+	"github.com/bigkevmcd/commit-status-tracker/test"
+)
+
+func TestStartTracing(t *testing.T) {
+	stop, err := StartTracing(TraceExporterNone, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop()
+
+	_, err = StartTracing("unknown", "", 1)
+	if !test.MatchError(t, `unknown trace exporter "unknown"`, err) {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestTracingTransport(t *testing.T) {
+	e := &MemoryExporter{}
+	trace.RegisterExporter(e)
+	defer trace.UnregisterExporter(e)
+	var traceID string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = r.Header.Get("X-B3-TraceId")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	ctx, parent := trace.StartSpan(context.Background(), "test-parent", trace.WithSampler(trace.AlwaysSample()))
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/repos/tektoncd/triggers/statuses/master", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := &http.Client{Transport: tracingTransport(http.DefaultTransport)}
+	res, err := cl.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	parent.End()
+
+	if want := parent.SpanContext().TraceID.String(); traceID != want {
+		t.Fatalf("request trace ID got %#v, want %#v", traceID, want)
+	}
+	s := e.Span("/repos/tektoncd/triggers/statuses/master")
+	if s == nil {
+		t.Fatalf("no span exported for the request, got %#v", e.Spans())
+	}
+	if s.ParentSpanID != parent.SpanContext().SpanID {
+		t.Fatalf("request span parent got %v, want %v", s.ParentSpanID, parent.SpanContext().SpanID)
+	}
+}
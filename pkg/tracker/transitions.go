@@ -0,0 +1,41 @@
+package tracker
+
+import "github.com/jenkins-x/go-scm/scm"
+
+// ValidTransition returns true if a run that was last reported in the from
+// state can be reported in the to state.
+//
+// Pending runs can move to any state, but runs that have completed can't
+// change state, so a stale Pending can't overwrite the completed state.
+func ValidTransition(from, to State) bool {
+	return from == Pending || from == to
+}
+
+// IsRetry returns true if the run has been retried since the last status was
+// reported, a retried run starts again from Pending.
+func IsRetry(last *ReportedStatus, retries int) bool {
+	if last == nil {
+		return retries > 0
+	}
+	return retries > last.Retries
+}
+
+// RunState returns the state of the run that was reported.
+func (r ReportedStatus) RunState() State {
+	switch r.State {
+	case scm.StateSuccess:
+		return Successful
+	case scm.StateFailure:
+		return Failed
+	case scm.StateError:
+		return Error
+	default:
+		return Pending
+	}
+}
+
+// RecordRejectedTransition counts a transition for a Kind that was not
+// reported.
+func RecordRejectedTransition(kind string, from, to State) {
+	rejectedTransitions.WithLabelValues(kind, from.String(), to.String()).Inc()
+}
@@ -0,0 +1,64 @@
+package tracker
+
+import (
+	"testing"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestValidTransition(t *testing.T) {
+	transitionTests := []struct {
+		from State
+		to   State
+		want bool
+	}{
+		{Pending, Pending, true},
+		{Pending, Successful, true},
+		{Pending, Failed, true},
+		{Successful, Successful, true},
+		{Successful, Pending, false},
+		{Failed, Pending, false},
+		{Failed, Successful, false},
+		{Error, Pending, false},
+	}
+
+	for _, tt := range transitionTests {
+		if b := ValidTransition(tt.from, tt.to); b != tt.want {
+			t.Errorf("ValidTransition(%s, %s) got %v, want %v", tt.from, tt.to, b, tt.want)
+		}
+	}
+}
+
+func TestIsRetry(t *testing.T) {
+	retryTests := []struct {
+		name    string
+		last    *ReportedStatus
+		retries int
+		want    bool
+	}{
+		{"nothing reported", nil, 0, false},
+		{"nothing reported for a retried run", nil, 1, true},
+		{"same attempt", &ReportedStatus{State: scm.StateFailure, Retries: 1}, 1, false},
+		{"retried since reported", &ReportedStatus{State: scm.StateFailure, Retries: 1}, 2, true},
+	}
+
+	for _, tt := range retryTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b := IsRetry(tt.last, tt.retries); b != tt.want {
+				t.Errorf("IsRetry() got %v, want %v", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordRejectedTransition(t *testing.T) {
+	counter := rejectedTransitions.WithLabelValues("TestRun", "Successful", "Pending")
+	before := testutil.ToFloat64(counter)
+
+	RecordRejectedTransition("TestRun", Successful, Pending)
+
+	if v := testutil.ToFloat64(counter); v != before+1 {
+		t.Fatalf("rejected transitions got %v, want %v", v, before+1)
+	}
+}